@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	app "github.com/youla-dev/schema/internal/app"
 
@@ -12,8 +14,16 @@ import (
 var Version = "0.0"
 
 func main() {
+	// The dotenv file is loaded before the profile of the config file, so its variables override the profile.
 	_ = godotenv.Load(os.Getenv("SCHEMA_CONFIG"))
 
+	// The first signal cancels the command, the second one terminates the utility at once.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	app := app.NewApp(Version)
-	app.Run(context.Background())
+	app.Run(ctx)
 }
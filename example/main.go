@@ -3,15 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-
-	"github.com/youla-dev/schema/lib/protoschema"
 )
 
+//go:generate schema gen --proto currency_message.proto,weather_message.proto --output topic_record.go
+
 func main() {
-	topic, record, err := protoschema.ExtractTopicRecord((*Currency)(nil), E_Topic, E_Record)
-	if err != nil {
-		panic(err)
-	}
+	topic, record := (*Currency)(nil).TopicRecord()
 	fmt.Printf("Currency topic %q, record %q\n", topic, record)
 
 	currency := Currency{
@@ -23,10 +20,7 @@ func main() {
 	d, _ := json.Marshal(currency)
 	fmt.Println(string(d))
 
-	topic, record, err = protoschema.ExtractTopicRecord((*Weather)(nil), E_Topic, E_Record)
-	if err != nil {
-		panic(err)
-	}
+	topic, record = (*Weather)(nil).TopicRecord()
 	fmt.Printf("Weather topic %q, record %q\n", topic, record)
 
 	weather := Weather{
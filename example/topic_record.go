@@ -0,0 +1,29 @@
+// Code generated by schema gen. DO NOT EDIT.
+// source: currency_message.proto
+// source: weather_message.proto
+
+package main
+
+// Values of the (topic) and (record) options of Currency.
+const (
+	CurrencyTopic   = "weather"
+	CurrencyRecord  = "currency"
+	CurrencySubject = "weather-currency-value"
+)
+
+// TopicRecord returns the values of the (topic) and (record) options of Currency.
+func (*Currency) TopicRecord() (string, string) {
+	return CurrencyTopic, CurrencyRecord
+}
+
+// Values of the (topic) and (record) options of Weather.
+const (
+	WeatherTopic   = "weather"
+	WeatherRecord  = "weather"
+	WeatherSubject = "weather-weather-value"
+)
+
+// TopicRecord returns the values of the (topic) and (record) options of Weather.
+func (*Weather) TopicRecord() (string, string) {
+	return WeatherTopic, WeatherRecord
+}
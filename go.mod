@@ -3,19 +3,23 @@ module github.com/youla-dev/schema
 go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.2.1
+	github.com/Shopify/sarama v1.21.0
 	github.com/bsm/sarama-cluster v2.1.15+incompatible
 	github.com/emicklei/proto v1.9.2
 	github.com/golang/protobuf v1.5.2
+	github.com/jhump/protoreflect v1.15.1
 	github.com/joho/godotenv v1.4.0
 	github.com/riferrei/srclient v0.5.4
 	github.com/urfave/cli/v2 v2.11.0
 	go.uber.org/dig v1.15.0
-	google.golang.org/protobuf v1.28.0
+	google.golang.org/protobuf v1.28.2-0.20230222093303-bc1253ad3743
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/DataDog/zstd v1.3.5 // indirect
-	github.com/Shopify/sarama v1.21.0 // indirect
+	github.com/bufbuild/protocompile v0.4.0 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.2 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/eapache/go-resiliency v1.2.0 // indirect
@@ -2,18 +2,27 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"time"
 
+	"github.com/Shopify/sarama"
 	saramaCluster "github.com/bsm/sarama-cluster"
 	"github.com/riferrei/srclient"
 	"github.com/urfave/cli/v2"
+	"github.com/youla-dev/schema/internal/changes"
 	"github.com/youla-dev/schema/internal/cmd"
+	"github.com/youla-dev/schema/internal/output"
+	"github.com/youla-dev/schema/lib/protoschema"
+	"github.com/youla-dev/schema/lib/schema"
 	"go.uber.org/dig"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 const (
@@ -24,6 +33,22 @@ const (
 	cmdInspect  = "inspect"
 	cmdSubjects = "subjects"
 	cmdExport   = "export"
+	cmdProduce  = "produce"
+	cmdConsume  = "consume"
+	cmdUndelete = "undelete"
+	cmdPrune    = "prune"
+	cmdID       = "id"
+	cmdGraph    = "graph"
+	cmdSearch   = "search"
+	cmdLint     = "lint"
+	cmdGen      = "gen"
+)
+
+const (
+	// registryAttemptTimeout limits every attempt of the Schema Registry request.
+	registryAttemptTimeout = 5 * time.Second
+	registryConcurrency    = 16
+	maxRetryBackoff        = 10 * time.Second
 )
 
 var (
@@ -34,6 +59,11 @@ var (
 		Usage:    "List of Kafka brokers joined with comma.",
 		EnvVars:  []string{"CLUSTER"},
 	}
+	FlagCluster = &cli.StringSliceFlag{
+		Name:    "cluster",
+		Usage:   "List of Kafka brokers joined with comma. The topic data is not read if empty.",
+		EnvVars: []string{"CLUSTER"},
+	}
 	FlagSRRequired = &cli.StringFlag{
 		Name:     "sr",
 		Required: true,
@@ -47,6 +77,56 @@ var (
 		Usage:   "URL for the Confluent Schema Registry.",
 		EnvVars: []string{"SCHEMA_REGISTRY"},
 	}
+	FlagSRUsername = &cli.StringFlag{
+		Name:    "sr-username",
+		Usage:   "Username for the basic authentication in the Schema Registry.",
+		EnvVars: []string{"SCHEMA_REGISTRY_USERNAME"},
+	}
+	FlagSRPassword = &cli.StringFlag{
+		Name:    "sr-password",
+		Usage:   "Password for the basic authentication in the Schema Registry.",
+		EnvVars: []string{"SCHEMA_REGISTRY_PASSWORD"},
+	}
+	FlagClusterTLS = &cli.BoolFlag{
+		Name:    "cluster-tls",
+		Usage:   "Connects to the Kafka brokers with TLS.",
+		EnvVars: []string{"CLUSTER_TLS"},
+	}
+	FlagClusterCAFile = &cli.StringFlag{
+		Name:    "cluster-ca-file",
+		Usage:   "CA certificate file to verify the Kafka brokers. The system pool is used if empty.",
+		EnvVars: []string{"CLUSTER_CA_FILE"},
+	}
+	FlagClusterCertFile = &cli.StringFlag{
+		Name:    "cluster-cert-file",
+		Usage:   "Client certificate file for TLS.",
+		EnvVars: []string{"CLUSTER_CERT_FILE"},
+	}
+	FlagClusterKeyFile = &cli.StringFlag{
+		Name:    "cluster-key-file",
+		Usage:   "Client key file for TLS.",
+		EnvVars: []string{"CLUSTER_KEY_FILE"},
+	}
+	FlagClusterTLSInsecure = &cli.BoolFlag{
+		Name:    "cluster-tls-insecure",
+		Usage:   "Skips the verification of the Kafka broker certificates.",
+		EnvVars: []string{"CLUSTER_TLS_INSECURE"},
+	}
+	FlagClusterSASLMechanism = &cli.StringFlag{
+		Name:    "cluster-sasl-mechanism",
+		Usage:   "SASL mechanism of the Kafka brokers. Only `PLAIN` is supported. SASL is disabled if empty.",
+		EnvVars: []string{"CLUSTER_SASL_MECHANISM"},
+	}
+	FlagClusterSASLUsername = &cli.StringFlag{
+		Name:    "cluster-sasl-username",
+		Usage:   "SASL username.",
+		EnvVars: []string{"CLUSTER_SASL_USERNAME"},
+	}
+	FlagClusterSASLPassword = &cli.StringFlag{
+		Name:    "cluster-sasl-password",
+		Usage:   "SASL password.",
+		EnvVars: []string{"CLUSTER_SASL_PASSWORD"},
+	}
 	FlagTopicRequired = &cli.StringFlag{
 		Name:     "topic",
 		Required: true,
@@ -65,16 +145,63 @@ var (
 		Usage:   "Name of the record within the topic.",
 		EnvVars: []string{"RECORD"},
 	}
+	FlagRecordOptional = &cli.StringFlag{
+		Name:    "record",
+		Usage:   "Name of the record within the topic. All records of the topic are used if empty.",
+		EnvVars: []string{"RECORD"},
+	}
 	FlagPermanent = &cli.BoolFlag{
 		Name:    "permanent",
 		Value:   false,
-		Usage:   "Permanent flag is used for the removing of version in 'hard' mode.",
+		Usage:   "Permanent flag is used for the removing of version in 'hard' mode. The version must be soft-deleted first.",
 		EnvVars: []string{"PERMANENT"},
 	}
-	FlagProtoRequired = &cli.StringFlag{
+	FlagForce = &cli.BoolFlag{
+		Name:    "force",
+		Usage:   "Deletes the schema even if it is used by the topic messages.",
+		EnvVars: []string{"FORCE"},
+	}
+	FlagScanWindow = &cli.Int64Flag{
+		Name:    "scan-window",
+		Value:   1000,
+		Usage:   "Number of the last messages of every partition to scan for the schema usage. Zero disables the scan.",
+		EnvVars: []string{"SCAN_WINDOW"},
+	}
+	FlagYes = &cli.BoolFlag{
+		Name:    "yes",
+		Usage:   "Skips the interactive confirmation, e.g. in CI.",
+		EnvVars: []string{"YES"},
+	}
+	FlagProtectedSubjects = &cli.StringSliceFlag{
+		Name:    "protected-subjects",
+		Usage:   "Regular expressions of the subjects which must not be deleted, joined with comma.",
+		EnvVars: []string{"PROTECTED_SUBJECTS"},
+	}
+	FlagIncludeDeleted = &cli.BoolFlag{
+		Name:    "include-deleted",
+		Usage:   "Includes the soft-deleted entries marked as deleted.",
+		EnvVars: []string{"INCLUDE_DELETED"},
+	}
+	FlagKeep = &cli.IntFlag{
+		Name:    "keep",
+		Usage:   "Number of the last versions to keep.",
+		EnvVars: []string{"KEEP"},
+	}
+	FlagOlderThan = &cli.DurationFlag{
+		Name:    "older-than",
+		Usage:   "Deletes only the versions registered earlier than the duration ago, e.g. `720h`.",
+		EnvVars: []string{"OLDER_THAN"},
+	}
+	FlagSchemasTopic = &cli.StringFlag{
+		Name:    "schemas-topic",
+		Value:   "_schemas",
+		Usage:   "Topic where the Schema Registry stores the schemas. Used to get the registration time of the versions.",
+		EnvVars: []string{"SCHEMAS_TOPIC"},
+	}
+	FlagProtoRequired = &cli.StringSliceFlag{
 		Name:     "proto",
 		Required: true,
-		Usage:    "File with Protobuf schema definition.",
+		Usage:    "File with Protobuf schema definition or compiled descriptor set (.pb, .binpb) or Buf image. Several files are joined with comma or set with several flags.",
 		EnvVars:  []string{"PROTO"},
 	}
 	FlagVersion = &cli.StringFlag{
@@ -83,6 +210,199 @@ var (
 		Usage:   "Version of the schema. E.g. `latest`, `1`, etc.",
 		EnvVars: []string{"VERSION"},
 	}
+	FlagJSONRequired = &cli.StringFlag{
+		Name:     "json",
+		Required: true,
+		Usage:    "Message value in JSON, e.g. '{\"city\": \"Terminus\"}'.",
+		EnvVars:  []string{"JSON"},
+	}
+	FlagKey = &cli.StringFlag{
+		Name:    "key",
+		Usage:   "Message key. The message is produced without a key if empty.",
+		EnvVars: []string{"KEY"},
+	}
+	FlagFromOffset = &cli.Int64Flag{
+		Name:    "from-offset",
+		Usage:   "Offset to read every partition from. The oldest available offset is used if the value is less.",
+		EnvVars: []string{"FROM_OFFSET"},
+	}
+	FlagLast = &cli.Int64Flag{
+		Name:    "last",
+		Usage:   "Number of the last messages to read from every partition. Overrides the offset.",
+		EnvVars: []string{"LAST"},
+	}
+	FlagSample = &cli.Int64Flag{
+		Name:    "sample",
+		Usage:   "Number of the last messages of every partition to decode with the registered and the validating schema.",
+		EnvVars: []string{"SAMPLE"},
+	}
+	FlagProtoPath = &cli.StringSliceFlag{
+		Name:    "proto-path",
+		Usage:   "Directories with proto files to build the graph of the imports from, joined with comma. The registry is used if empty.",
+		EnvVars: []string{"PROTO_PATH"},
+	}
+	FlagGraphFormat = &cli.StringFlag{
+		Name:    "format",
+		Value:   cmd.GraphFormatDOT,
+		Usage:   "Format of the graph: `dot`, `mermaid` or `json`.",
+		EnvVars: []string{"GRAPH_FORMAT"},
+	}
+	FlagExportFormat = &cli.StringFlag{
+		Name:    "format",
+		Value:   cmd.ExportFormatProto,
+		Usage:   "Format of the exported schema: `proto` text or compiled `descriptor-set` with the references.",
+		EnvVars: []string{"EXPORT_FORMAT"},
+	}
+	FlagSourceInfo = &cli.BoolFlag{
+		Name:    "source-info",
+		Usage:   "Keep the source code info, e.g. the comments, in the descriptor set.",
+		EnvVars: []string{"SOURCE_INFO"},
+	}
+	FlagDependsOn = &cli.StringFlag{
+		Name:    "depends-on",
+		Usage:   "Limits the graph to the schemas depending on the subject, `subject@version` or the proto file.",
+		EnvVars: []string{"DEPENDS_ON"},
+	}
+	FlagPullsIn = &cli.StringFlag{
+		Name:    "pulls-in",
+		Usage:   "Limits the graph to the schemas pulled in by the subject, `subject@version` or the proto file.",
+		EnvVars: []string{"PULLS_IN"},
+	}
+	FlagTopicGlob = &cli.StringFlag{
+		Name:    "topic",
+		Usage:   "Glob of the topic name, e.g. `orders.*`. All topics are matched if empty.",
+		EnvVars: []string{"TOPIC"},
+	}
+	FlagRecordGlob = &cli.StringFlag{
+		Name:    "record",
+		Usage:   "Glob of the record name. All records are matched if empty.",
+		EnvVars: []string{"RECORD"},
+	}
+	FlagContains = &cli.StringFlag{
+		Name:    "contains",
+		Usage:   "Glob of the message or field name the latest version of the schema must contain.",
+		EnvVars: []string{"CONTAINS"},
+	}
+	FlagProto = &cli.StringSliceFlag{
+		Name:    "proto",
+		Usage:   "File with Protobuf schema definition or compiled descriptor set (.pb, .binpb) or Buf image. Several files are joined with comma or set with several flags.",
+		EnvVars: []string{"PROTO"},
+	}
+	FlagChangedSince = &cli.StringFlag{
+		Name:    "changed-since",
+		Usage:   "Git reference to validate only the proto files changed since, e.g. `origin/master`. The files importing the changed ones are validated too. All proto files of the working directory are checked if --proto is not set.",
+		EnvVars: []string{"CHANGED_SINCE"},
+	}
+	FlagLint = &cli.BoolFlag{
+		Name:    "lint",
+		Usage:   "Lints the proto files before the validation.",
+		EnvVars: []string{"LINT"},
+	}
+	FlagLintConfig = &cli.StringFlag{
+		Name:    "lint-config",
+		Usage:   "Lint config file. The .schema-lint.yaml of the nearest directory of every proto file is used if empty.",
+		EnvVars: []string{"LINT_CONFIG"},
+	}
+	FlagGoPackage = &cli.StringFlag{
+		Name:    "go-package",
+		Usage:   "Go package of the generated code. The package of the go_package option is used if empty.",
+		EnvVars: []string{"GO_PACKAGE"},
+	}
+	FlagPolicy = &cli.StringFlag{
+		Name:    "policy",
+		Usage:   "Policy rule file evaluated on the changes between the registered and the validated schema.",
+		EnvVars: []string{"POLICY"},
+	}
+	FlagReport = &cli.StringFlag{
+		Name:    "report",
+		Usage:   "Writes the report for CI instead of the result: `junit`, `sarif` or `github` annotations.",
+		EnvVars: []string{"REPORT"},
+	}
+	FlagOutput = &cli.StringFlag{
+		Name:    "output",
+		Usage:   "Output file. The standard output is used if empty.",
+		EnvVars: []string{"OUTPUT"},
+	}
+	FlagConfig = &cli.StringFlag{
+		Name:    "config",
+		Usage:   "Config file with the profiles in YAML or TOML. .schema.yaml, .schema.yml or .schema.toml of the working directory or config.yaml, config.yml or config.toml of the schema user config directory is used if empty.",
+		EnvVars: []string{"SCHEMA_CONFIG_FILE"},
+	}
+	FlagProfile = &cli.StringFlag{
+		Name:    "profile",
+		Usage:   "Profile of the config file, e.g. `stage`. The default profile of the config is used if empty.",
+		EnvVars: []string{"SCHEMA_PROFILE"},
+	}
+	FlagTimeout = &cli.DurationFlag{
+		Name:    "timeout",
+		Usage:   "Timeout of the command, e.g. `30s`. The command is not limited if zero.",
+		EnvVars: []string{"TIMEOUT"},
+	}
+	FlagRetries = &cli.IntFlag{
+		Name:    "retries",
+		Value:   3,
+		Usage:   "Number of the retries of the Schema Registry and Kafka calls failed with the network errors or 5xx responses.",
+		EnvVars: []string{"RETRIES"},
+	}
+	FlagRetryBackoff = &cli.DurationFlag{
+		Name:    "retry-backoff",
+		Value:   500 * time.Millisecond,
+		Usage:   "Delay before the first retry, doubled for every next one.",
+		EnvVars: []string{"RETRY_BACKOFF"},
+	}
+	FlagCacheTTL = &cli.DurationFlag{
+		Name:    "cache-ttl",
+		Usage:   "Keep the Schema Registry responses in the cache file for the next runs, e.g. `10m`. The cache is kept for the run only if zero.",
+		EnvVars: []string{"CACHE_TTL"},
+	}
+	FlagCacheFile = &cli.StringFlag{
+		Name:    "cache-file",
+		Usage:   "Cache file of the Schema Registry responses. schema/registry-cache.json of the user cache directory is used if empty.",
+		EnvVars: []string{"CACHE_FILE"},
+	}
+	FlagTopicOption = &cli.StringFlag{
+		Name:    "topic-option",
+		Value:   protoschema.TopicOption.Name,
+		Usage:   "Name of the message option of the topic, e.g. `kafka.topic`. The short name matches the option of any package.",
+		EnvVars: []string{"TOPIC_OPTION"},
+	}
+	FlagRecordOption = &cli.StringFlag{
+		Name:    "record-option",
+		Value:   protoschema.RecordOption.Name,
+		Usage:   "Name of the message option of the record, e.g. `kafka.record`. The short name matches the option of any package.",
+		EnvVars: []string{"RECORD_OPTION"},
+	}
+	FlagFileTopicOption = &cli.StringFlag{
+		Name:    "file-topic-option",
+		Usage:   "Name of the file option of the default topic of the messages, e.g. `kafka.file_topic`. The topic option name is used if empty.",
+		EnvVars: []string{"FILE_TOPIC_OPTION"},
+	}
+	FlagFileRecordOption = &cli.StringFlag{
+		Name:    "file-record-option",
+		Usage:   "Name of the file option of the default record of the messages. The record option name is used if empty.",
+		EnvVars: []string{"FILE_RECORD_OPTION"},
+	}
+	FlagTopicOptionNumber = &cli.IntFlag{
+		Name:    "topic-option-number",
+		Usage:   "Field number of the topic option read from the descriptor sets without its declaration. The declaration is required if zero.",
+		EnvVars: []string{"TOPIC_OPTION_NUMBER"},
+	}
+	FlagRecordOptionNumber = &cli.IntFlag{
+		Name:    "record-option-number",
+		Usage:   "Field number of the record option read from the descriptor sets without its declaration. The declaration is required if zero.",
+		EnvVars: []string{"RECORD_OPTION_NUMBER"},
+	}
+	FlagOutputFormat = &cli.StringFlag{
+		Name:    "output-format",
+		Value:   output.FormatText,
+		Usage:   "Format of the command result: `text`, `json`, `yaml`, `table` or `template`.",
+		EnvVars: []string{"OUTPUT_FORMAT"},
+	}
+	FlagTemplate = &cli.StringFlag{
+		Name:    "template",
+		Usage:   "Go template of the command result for the template output format, e.g. '{{range .}}{{.Subject}}{{\"\\n\"}}{{end}}'.",
+		EnvVars: []string{"TEMPLATE"},
+	}
 	FlagOutputRequired = &cli.StringFlag{
 		Name:     "output",
 		Required: true,
@@ -91,15 +411,60 @@ var (
 	}
 )
 
+// SRAuth holds the Schema Registry credentials.
+type SRAuth struct {
+	Username string
+	Password string
+}
+
+// ClusterSecurity holds the TLS and SASL settings of the Kafka brokers.
+type ClusterSecurity struct {
+	TLS                bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+	SASLMechanism      string
+	SASLUsername       string
+	SASLPassword       string
+}
+
 type (
-	ClusterFlag   []string
-	SRFlag        string
-	TopicFlag     string
-	RecordFlag    string
-	PermanentFlag bool
-	ProtoFlag     string
-	VersionFlag   int
-	OutputFlag    string
+	ClusterFlag           []string
+	SRFlag                string
+	TopicFlag             string
+	RecordFlag            string
+	PermanentFlag         bool
+	ProtoFlag             []string
+	VersionFlag           int
+	OutputFlag            string
+	JSONFlag              string
+	KeyFlag               string
+	FromOffsetFlag        int64
+	LastFlag              int64
+	SampleFlag            int64
+	ForceFlag             bool
+	ScanWindowFlag        int64
+	YesFlag               bool
+	ProtectedSubjectsFlag []*regexp.Regexp
+	IncludeDeletedFlag    bool
+	KeepFlag              int
+	OlderThanFlag         time.Duration
+	SchemasTopicFlag      string
+	SchemaIDArg           int
+	ProtoPathFlag         []string
+	GraphFormatFlag       string
+	ExportFormatFlag      string
+	SourceInfoFlag        bool
+	DependsOnFlag         string
+	PullsInFlag           string
+	ContainsFlag          string
+	ReportFlag            string
+	ChangedSinceFlag      string
+	LintFlag              bool
+	LintConfigFlag        string
+	PolicyFlag            string
+	GoPackageFlag         string
 )
 
 func GetClusterFlag(c *cli.Context) ClusterFlag {
@@ -123,7 +488,31 @@ func GetPermanentFlag(c *cli.Context) PermanentFlag {
 }
 
 func GetProtoFlag(c *cli.Context) ProtoFlag {
-	return ProtoFlag(c.String(FlagProtoRequired.Name))
+	return c.StringSlice(FlagProtoRequired.Name)
+}
+
+func GetChangedSinceFlag(c *cli.Context) ChangedSinceFlag {
+	return ChangedSinceFlag(c.String(FlagChangedSince.Name))
+}
+
+func GetLintFlag(c *cli.Context) LintFlag {
+	return LintFlag(c.Bool(FlagLint.Name))
+}
+
+func GetLintConfigFlag(c *cli.Context) LintConfigFlag {
+	return LintConfigFlag(c.String(FlagLintConfig.Name))
+}
+
+func GetGoPackageFlag(c *cli.Context) GoPackageFlag {
+	return GoPackageFlag(c.String(FlagGoPackage.Name))
+}
+
+func GetPolicyFlag(c *cli.Context) PolicyFlag {
+	return PolicyFlag(c.String(FlagPolicy.Name))
+}
+
+func GetReportFlag(c *cli.Context) ReportFlag {
+	return ReportFlag(c.String(FlagReport.Name))
 }
 
 func GetVersionFlag(c *cli.Context) (VersionFlag, error) {
@@ -143,8 +532,171 @@ func GetOutputFlag(c *cli.Context) OutputFlag {
 	return OutputFlag(c.String(FlagOutputRequired.Name))
 }
 
-func GetClusterClient(connection ClusterFlag) (*saramaCluster.Client, error) {
+func GetJSONFlag(c *cli.Context) JSONFlag {
+	return JSONFlag(c.String(FlagJSONRequired.Name))
+}
+
+func GetKeyFlag(c *cli.Context) KeyFlag {
+	return KeyFlag(c.String(FlagKey.Name))
+}
+
+func GetFromOffsetFlag(c *cli.Context) FromOffsetFlag {
+	return FromOffsetFlag(c.Int64(FlagFromOffset.Name))
+}
+
+func GetLastFlag(c *cli.Context) LastFlag {
+	return LastFlag(c.Int64(FlagLast.Name))
+}
+
+func GetSampleFlag(c *cli.Context) SampleFlag {
+	return SampleFlag(c.Int64(FlagSample.Name))
+}
+
+func GetForceFlag(c *cli.Context) ForceFlag {
+	return ForceFlag(c.Bool(FlagForce.Name))
+}
+
+func GetScanWindowFlag(c *cli.Context) ScanWindowFlag {
+	return ScanWindowFlag(c.Int64(FlagScanWindow.Name))
+}
+
+func GetYesFlag(c *cli.Context) YesFlag {
+	return YesFlag(c.Bool(FlagYes.Name))
+}
+
+func GetProtectedSubjectsFlag(c *cli.Context) (ProtectedSubjectsFlag, error) {
+	var patterns ProtectedSubjectsFlag
+	for _, value := range c.StringSlice(FlagProtectedSubjects.Name) {
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("protected subject pattern %q is invalid: %w", value, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+func GetIncludeDeletedFlag(c *cli.Context) IncludeDeletedFlag {
+	return IncludeDeletedFlag(c.Bool(FlagIncludeDeleted.Name))
+}
+
+func GetKeepFlag(c *cli.Context) KeepFlag {
+	return KeepFlag(c.Int(FlagKeep.Name))
+}
+
+func GetOlderThanFlag(c *cli.Context) OlderThanFlag {
+	return OlderThanFlag(c.Duration(FlagOlderThan.Name))
+}
+
+func GetSchemasTopicFlag(c *cli.Context) SchemasTopicFlag {
+	return SchemasTopicFlag(c.String(FlagSchemasTopic.Name))
+}
+
+func GetProtoPathFlag(c *cli.Context) ProtoPathFlag {
+	return c.StringSlice(FlagProtoPath.Name)
+}
+
+func GetGraphFormatFlag(c *cli.Context) GraphFormatFlag {
+	return GraphFormatFlag(c.String(FlagGraphFormat.Name))
+}
+
+func GetExportFormatFlag(c *cli.Context) ExportFormatFlag {
+	return ExportFormatFlag(c.String(FlagExportFormat.Name))
+}
+
+func GetSourceInfoFlag(c *cli.Context) SourceInfoFlag {
+	return SourceInfoFlag(c.Bool(FlagSourceInfo.Name))
+}
+
+func GetDependsOnFlag(c *cli.Context) DependsOnFlag {
+	return DependsOnFlag(c.String(FlagDependsOn.Name))
+}
+
+func GetPullsInFlag(c *cli.Context) PullsInFlag {
+	return PullsInFlag(c.String(FlagPullsIn.Name))
+}
+
+func GetContainsFlag(c *cli.Context) ContainsFlag {
+	return ContainsFlag(c.String(FlagContains.Name))
+}
+
+func GetSchemaIDArg(c *cli.Context) (SchemaIDArg, error) {
+	id, err := strconv.Atoi(c.Args().First())
+	if err != nil {
+		return 0, fmt.Errorf("schema ID is invalid: %w", err)
+	}
+	return SchemaIDArg(id), nil
+}
+
+func GetRetryPolicy(c *cli.Context) schema.RetryPolicy {
+	return schema.RetryPolicy{
+		Retries:    c.Int(FlagRetries.Name),
+		Backoff:    c.Duration(FlagRetryBackoff.Name),
+		MaxBackoff: maxRetryBackoff,
+	}
+}
+
+func GetOptions(c *cli.Context) (protoschema.Options, error) {
+	topic, err := newOption(c.String(FlagTopicOption.Name), c.Int(FlagTopicOptionNumber.Name))
+	if err != nil {
+		return protoschema.Options{}, err
+	}
+	record, err := newOption(c.String(FlagRecordOption.Name), c.Int(FlagRecordOptionNumber.Name))
+	if err != nil {
+		return protoschema.Options{}, err
+	}
+	return protoschema.Options{
+		Topic:      topic,
+		Record:     record,
+		FileTopic:  protoschema.NewOption(c.String(FlagFileTopicOption.Name), 0),
+		FileRecord: protoschema.NewOption(c.String(FlagFileRecordOption.Name), 0),
+	}, nil
+}
+
+func newOption(name string, number int) (protoschema.Option, error) {
+	option := protoschema.NewOption(name, protoreflect.FieldNumber(number))
+	if option.Name == "" {
+		return protoschema.Option{}, errors.New("option name is not set")
+	}
+	if number != 0 && !option.Number.IsValid() {
+		return protoschema.Option{}, fmt.Errorf("invalid field number %d of option (%s)", number, option.Name)
+	}
+	return option, nil
+}
+
+func GetSRAuth(c *cli.Context) SRAuth {
+	return SRAuth{
+		Username: c.String(FlagSRUsername.Name),
+		Password: c.String(FlagSRPassword.Name),
+	}
+}
+
+func GetClusterSecurity(c *cli.Context) ClusterSecurity {
+	return ClusterSecurity{
+		TLS:                c.Bool(FlagClusterTLS.Name),
+		CAFile:             c.String(FlagClusterCAFile.Name),
+		CertFile:           c.String(FlagClusterCertFile.Name),
+		KeyFile:            c.String(FlagClusterKeyFile.Name),
+		InsecureSkipVerify: c.Bool(FlagClusterTLSInsecure.Name),
+		SASLMechanism:      c.String(FlagClusterSASLMechanism.Name),
+		SASLUsername:       c.String(FlagClusterSASLUsername.Name),
+		SASLPassword:       c.String(FlagClusterSASLPassword.Name),
+	}
+}
+
+func GetClusterClient(connection ClusterFlag, security ClusterSecurity, retry schema.RetryPolicy) (*saramaCluster.Client, error) {
 	kfkCfg := saramaCluster.NewConfig()
+	kfkCfg.Version = sarama.V0_11_0_0
+	kfkCfg.Producer.Return.Successes = true
+	kfkCfg.Metadata.Retry.Max = retry.Retries
+	kfkCfg.Metadata.Retry.Backoff = retry.Backoff
+	kfkCfg.Producer.Retry.Max = retry.Retries
+	kfkCfg.Producer.Retry.Backoff = retry.Backoff
+	kfkCfg.Consumer.Retry.Backoff = retry.Backoff
+	kfkCfg.Consumer.Return.Errors = true
+	if err := security.apply(&kfkCfg.Config); err != nil {
+		return nil, err
+	}
 	clusterClient, err := saramaCluster.NewClient(connection, kfkCfg)
 	if err != nil {
 		return nil, fmt.Errorf("can not create cluster client %v: %w", connection, err)
@@ -152,17 +704,70 @@ func GetClusterClient(connection ClusterFlag) (*saramaCluster.Client, error) {
 	return clusterClient, nil
 }
 
-func GetSRClient(connection SRFlag) srclient.ISchemaRegistryClient {
-	return srclient.CreateSchemaRegistryClient(string(connection))
+func GetSRClient(
+	c *cli.Context,
+	connection SRFlag,
+	auth SRAuth,
+	retry schema.RetryPolicy,
+) (srclient.ISchemaRegistryClient, error) {
+	client := srclient.CreateSchemaRegistryClientWithOptions(
+		string(connection),
+		schema.NewHTTPClient(c.Context, retry, registryAttemptTimeout),
+		registryConcurrency,
+	)
+	if auth.Username != "" {
+		client.SetCredentials(auth.Username, auth.Password)
+	}
+
+	cache := schema.NewCachingClient(client)
+	ttl := c.Duration(FlagCacheTTL.Name)
+	if ttl <= 0 {
+		return cache, nil
+	}
+	path := c.String(FlagCacheFile.Name)
+	if path == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("can not find user cache directory: %w", err)
+		}
+		path = filepath.Join(dir, "schema", "registry-cache.json")
+	}
+	if err := cache.Load(path, string(connection), ttl); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+func saveRegistryCache(client srclient.ISchemaRegistryClient) error {
+	if cache, ok := client.(*schema.CachingClient); ok {
+		return cache.Save()
+	}
+	return nil
+}
+
+func GetRegistry(c *cli.Context, connection SRFlag, auth SRAuth, retry schema.RetryPolicy) *schema.Registry {
+	registry := schema.NewRegistry(string(connection))
+	registry.SetHTTPClient(schema.NewHTTPClient(c.Context, retry, registryAttemptTimeout))
+	if auth.Username != "" {
+		registry.SetCredentials(auth.Username, auth.Password)
+	}
+	return registry
 }
 
 func GetInspect(
 	schemaRegistryClient srclient.ISchemaRegistryClient,
+	registry *schema.Registry,
 	topic TopicFlag,
 	record RecordFlag,
 	version VersionFlag,
+	includeDeleted IncludeDeletedFlag,
 ) (*cmd.Inspect, error) {
-	return cmd.NewInspect(schemaRegistryClient, string(topic), string(record), int(version))
+	return cmd.NewInspect(schema.NewClient(schemaRegistryClient, nil).WithRegistry(registry), schema.InspectRequest{
+		Topic:          string(topic),
+		Record:         string(record),
+		Version:        int(version),
+		IncludeDeleted: bool(includeDeleted),
+	})
 }
 
 func GetRegister(
@@ -170,49 +775,197 @@ func GetRegister(
 	schemaRegistryClient srclient.ISchemaRegistryClient,
 	topic TopicFlag,
 	record RecordFlag,
-	protoFile ProtoFlag,
+	protoFiles ProtoFlag,
+	reportFormat ReportFlag,
+	options protoschema.Options,
 ) (*cmd.Register, error) {
-	schemaBytes, err := os.ReadFile(string(protoFile))
+	files, err := readProtoFiles(protoFiles, options)
 	if err != nil {
-		return nil, fmt.Errorf("error reading schema: %w", err)
+		return nil, err
 	}
-	return cmd.NewRegister(schemaRegistryClient, clusterClient, string(topic), string(record), schemaBytes)
+	client := schema.NewClient(schemaRegistryClient, clusterClient).WithOptions(options)
+	return cmd.NewRegister(client, schema.RegisterRequest{
+		Topic:  string(topic),
+		Record: string(record),
+	}, files, string(reportFormat))
 }
 
 func GetValidate(
 	clusterClient *saramaCluster.Client,
 	schemaRegistryClient srclient.ISchemaRegistryClient,
+	registry *schema.Registry,
 	topic TopicFlag,
 	record RecordFlag,
-	protoFile ProtoFlag,
+	protoFiles ProtoFlag,
+	sample SampleFlag,
+	reportFormat ReportFlag,
+	changedSince ChangedSinceFlag,
+	lintFiles LintFlag,
+	lintConfig LintConfigFlag,
+	policyFile PolicyFlag,
+	options protoschema.Options,
+	c *cli.Context,
 ) (*cmd.Validate, error) {
-	schemaBytes, err := os.ReadFile(string(protoFile))
+	paths := []string(protoFiles)
+	var deleted []cmd.ProtoFile
+	if changedSince != "" {
+		found, err := changes.Find(c.Context, string(changedSince), paths)
+		if err != nil {
+			return nil, fmt.Errorf("can not find changed proto files: %w", err)
+		}
+		paths = found.Affected
+		for _, file := range found.Deleted {
+			deleted = append(deleted, cmd.ProtoFile{Path: file.Path, Schema: file.Schema})
+		}
+	} else if len(paths) == 0 {
+		return nil, errors.New("proto files are not set: set --proto or --changed-since")
+	}
+
+	files, err := readProtoFiles(paths, options)
+	if err != nil {
+		return nil, err
+	}
+	var linter *cmd.Lint
+	if lintFiles {
+		linter, err = cmd.NewLint(files, string(lintConfig), string(reportFormat), options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var policy *schema.Policy
+	if policyFile != "" {
+		policy, err = schema.LoadPolicy(string(policyFile))
+		if err != nil {
+			return nil, err
+		}
+	}
+	client := schema.NewClient(schemaRegistryClient, clusterClient).WithRegistry(registry).WithOptions(options)
+	return cmd.NewValidate(client, schema.ValidateRequest{
+		Topic:  string(topic),
+		Record: string(record),
+		Sample: int64(sample),
+		Policy: policy,
+	}, files, deleted, linter, string(reportFormat))
+}
+
+func GetLint(
+	protoFiles ProtoFlag,
+	lintConfig LintConfigFlag,
+	reportFormat ReportFlag,
+	options protoschema.Options,
+) (*cmd.Lint, error) {
+	files, err := readProtoFiles(protoFiles, options)
+	if err != nil {
+		return nil, err
+	}
+	return cmd.NewLint(files, string(lintConfig), string(reportFormat), options)
+}
+
+func GetGen(protoFiles ProtoFlag, goPackage GoPackageFlag, options protoschema.Options) (*cmd.Gen, error) {
+	files, err := readProtoFiles(protoFiles, options)
 	if err != nil {
-		return nil, fmt.Errorf("error reading schema: %w", err)
+		return nil, err
+	}
+	return cmd.NewGen(files, string(goPackage), options)
+}
+
+func readProtoFiles(paths []string, options protoschema.Options) ([]cmd.ProtoFile, error) {
+	files := make([]cmd.ProtoFile, 0, len(paths))
+	for _, path := range paths {
+		schemaBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading schema: %w", err)
+		}
+		if !protoschema.IsDescriptorSet(path) {
+			files = append(files, cmd.ProtoFile{Path: path, Schema: schemaBytes})
+			continue
+		}
+		// Every message with the topic&record options of the descriptor set is the separate schema.
+		schemas, err := options.FromDescriptorSet(schemaBytes)
+		if err != nil {
+			return nil, fmt.Errorf("can not read descriptor set %q: %w", path, err)
+		}
+		for _, s := range schemas {
+			files = append(files, cmd.ProtoFile{Path: path, Schema: s.Schema})
+		}
 	}
-	return cmd.NewValidate(schemaRegistryClient, clusterClient, string(topic), string(record), schemaBytes)
+	return files, nil
 }
 
 func GetDelete(
+	connection ClusterFlag,
+	security ClusterSecurity,
+	retry schema.RetryPolicy,
 	schemaRegistryClient srclient.ISchemaRegistryClient,
+	registry *schema.Registry,
 	topic TopicFlag,
 	record RecordFlag,
 	version VersionFlag,
 	permanent PermanentFlag,
+	force ForceFlag,
+	scanWindow ScanWindowFlag,
+	yes YesFlag,
+	protectedSubjects ProtectedSubjectsFlag,
 ) (*cmd.Delete, error) {
-	return cmd.NewDelete(schemaRegistryClient, string(topic), string(record), int(version), bool(permanent))
+	// The cluster is needed only to scan the topic data for the schema usage.
+	var clusterClient sarama.Client
+	if len(connection) > 0 && scanWindow > 0 {
+		cluster, err := GetClusterClient(connection, security, retry)
+		if err != nil {
+			return nil, err
+		}
+		clusterClient = cluster
+	}
+	client := schema.NewClient(schemaRegistryClient, clusterClient).
+		WithRegistry(registry).
+		WithProtectedSubjects(protectedSubjects...)
+	return cmd.NewDelete(client, schema.DeleteRequest{
+		Topic:      string(topic),
+		Record:     string(record),
+		Version:    int(version),
+		Permanent:  bool(permanent),
+		Force:      bool(force),
+		ScanWindow: int64(scanWindow),
+	}, bool(yes))
 }
 
 func GetVersions(
 	schemaRegistryClient srclient.ISchemaRegistryClient,
+	registry *schema.Registry,
 	topic TopicFlag,
 	record RecordFlag,
+	includeDeleted IncludeDeletedFlag,
 ) (*cmd.Versions, error) {
-	return cmd.NewVersions(schemaRegistryClient, string(topic), string(record))
+	return cmd.NewVersions(schema.NewClient(schemaRegistryClient, nil).WithRegistry(registry), schema.VersionsRequest{
+		Topic:          string(topic),
+		Record:         string(record),
+		IncludeDeleted: bool(includeDeleted),
+	})
 }
 
-func GetSubjects(schemaRegistryClient srclient.ISchemaRegistryClient, topic TopicFlag) (*cmd.Subjects, error) {
-	return cmd.NewSubjects(schemaRegistryClient, string(topic))
+func GetSubjects(
+	schemaRegistryClient srclient.ISchemaRegistryClient,
+	topic TopicFlag,
+	includeDeleted IncludeDeletedFlag,
+) (*cmd.Subjects, error) {
+	return cmd.NewSubjects(schema.NewClient(schemaRegistryClient, nil), schema.SubjectsRequest{
+		Topic:          string(topic),
+		IncludeDeleted: bool(includeDeleted),
+	})
+}
+
+func GetUndelete(
+	schemaRegistryClient srclient.ISchemaRegistryClient,
+	registry *schema.Registry,
+	topic TopicFlag,
+	record RecordFlag,
+	version VersionFlag,
+) (*cmd.Undelete, error) {
+	return cmd.NewUndelete(schema.NewClient(schemaRegistryClient, nil).WithRegistry(registry), schema.UndeleteRequest{
+		Topic:   string(topic),
+		Record:  string(record),
+		Version: int(version),
+	})
 }
 
 func GetExport(
@@ -220,8 +973,130 @@ func GetExport(
 	topic TopicFlag,
 	record RecordFlag,
 	version VersionFlag,
+	format ExportFormatFlag,
+	sourceInfo SourceInfoFlag,
 ) (*cmd.Export, error) {
-	return cmd.NewExport(schemaRegistryClient, string(topic), string(record), int(version))
+	return cmd.NewExport(schema.NewClient(schemaRegistryClient, nil), schema.ExportRequest{
+		Topic:      string(topic),
+		Record:     string(record),
+		Version:    int(version),
+		SourceInfo: bool(sourceInfo),
+	}, string(format))
+}
+
+func GetProduce(
+	clusterClient *saramaCluster.Client,
+	schemaRegistryClient srclient.ISchemaRegistryClient,
+	topic TopicFlag,
+	record RecordFlag,
+	version VersionFlag,
+	json JSONFlag,
+	key KeyFlag,
+	options protoschema.Options,
+) (*cmd.Produce, error) {
+	request := schema.ProduceRequest{
+		Topic:   string(topic),
+		Record:  string(record),
+		Version: int(version),
+		JSON:    []byte(json),
+	}
+	if key != "" {
+		request.Key = []byte(key)
+	}
+	return cmd.NewProduce(schema.NewClient(schemaRegistryClient, clusterClient).WithOptions(options), request)
+}
+
+func GetConsume(
+	clusterClient *saramaCluster.Client,
+	schemaRegistryClient srclient.ISchemaRegistryClient,
+	registry *schema.Registry,
+	topic TopicFlag,
+	fromOffset FromOffsetFlag,
+	last LastFlag,
+) (*cmd.Consume, error) {
+	client := schema.NewClient(schemaRegistryClient, clusterClient).WithRegistry(registry)
+	return cmd.NewConsume(client, schema.ConsumeRequest{
+		Topic:      string(topic),
+		FromOffset: int64(fromOffset),
+		Last:       int64(last),
+	})
+}
+
+func GetPrune(
+	connection ClusterFlag,
+	security ClusterSecurity,
+	retry schema.RetryPolicy,
+	schemaRegistryClient srclient.ISchemaRegistryClient,
+	registry *schema.Registry,
+	topic TopicFlag,
+	record RecordFlag,
+	keep KeepFlag,
+	olderThan OlderThanFlag,
+	permanent PermanentFlag,
+	schemasTopic SchemasTopicFlag,
+	yes YesFlag,
+	protectedSubjects ProtectedSubjectsFlag,
+) (*cmd.Prune, error) {
+	// The cluster is needed only to read the registration time of the versions.
+	var clusterClient sarama.Client
+	if len(connection) > 0 {
+		cluster, err := GetClusterClient(connection, security, retry)
+		if err != nil {
+			return nil, err
+		}
+		clusterClient = cluster
+	}
+	client := schema.NewClient(schemaRegistryClient, clusterClient).
+		WithRegistry(registry).
+		WithProtectedSubjects(protectedSubjects...)
+	return cmd.NewPrune(client, schema.PruneRequest{
+		Topic:        string(topic),
+		Record:       string(record),
+		Keep:         int(keep),
+		OlderThan:    time.Duration(olderThan),
+		Permanent:    bool(permanent),
+		SchemasTopic: string(schemasTopic),
+	}, bool(yes))
+}
+
+func GetSchemaID(
+	schemaRegistryClient srclient.ISchemaRegistryClient,
+	registry *schema.Registry,
+	id SchemaIDArg,
+) (*cmd.SchemaID, error) {
+	client := schema.NewClient(schemaRegistryClient, nil).WithRegistry(registry)
+	return cmd.NewSchemaID(client, schema.SchemaByIDRequest{
+		ID: int(id),
+	})
+}
+
+func GetGraph(
+	schemaRegistryClient srclient.ISchemaRegistryClient,
+	registry *schema.Registry,
+	protoPath ProtoPathFlag,
+	format GraphFormatFlag,
+	dependsOn DependsOnFlag,
+	pullsIn PullsInFlag,
+) (*cmd.Graph, error) {
+	client := schema.NewClient(schemaRegistryClient, nil).WithRegistry(registry)
+	return cmd.NewGraph(client, schema.GraphRequest{
+		ProtoPaths: protoPath,
+		DependsOn:  string(dependsOn),
+		PullsIn:    string(pullsIn),
+	}, string(format))
+}
+
+func GetSearch(
+	schemaRegistryClient srclient.ISchemaRegistryClient,
+	topic TopicFlag,
+	record RecordFlag,
+	contains ContainsFlag,
+) (*cmd.Search, error) {
+	return cmd.NewSearch(schema.NewClient(schemaRegistryClient, nil), schema.SearchRequest{
+		Topic:    string(topic),
+		Record:   string(record),
+		Contains: string(contains),
+	})
 }
 
 type App struct {
@@ -241,8 +1116,40 @@ func NewApp(version string) *App {
 		GetProtoFlag,
 		GetVersionFlag,
 		GetOutputFlag,
+		GetJSONFlag,
+		GetKeyFlag,
+		GetFromOffsetFlag,
+		GetLastFlag,
+		GetSampleFlag,
+		GetForceFlag,
+		GetScanWindowFlag,
+		GetYesFlag,
+		GetProtectedSubjectsFlag,
+		GetIncludeDeletedFlag,
+		GetKeepFlag,
+		GetOlderThanFlag,
+		GetSchemasTopicFlag,
+		GetSchemaIDArg,
+		GetProtoPathFlag,
+		GetGraphFormatFlag,
+		GetExportFormatFlag,
+		GetSourceInfoFlag,
+		GetDependsOnFlag,
+		GetPullsInFlag,
+		GetContainsFlag,
+		GetReportFlag,
+		GetChangedSinceFlag,
+		GetLintFlag,
+		GetLintConfigFlag,
+		GetPolicyFlag,
+		GetGoPackageFlag,
+		GetSRAuth,
+		GetClusterSecurity,
+		GetRetryPolicy,
+		GetOptions,
 		GetClusterClient,
 		GetSRClient,
+		GetRegistry,
 		// Actions
 		GetInspect,
 		GetRegister,
@@ -251,6 +1158,15 @@ func NewApp(version string) *App {
 		GetVersions,
 		GetSubjects,
 		GetExport,
+		GetProduce,
+		GetConsume,
+		GetUndelete,
+		GetPrune,
+		GetSchemaID,
+		GetGraph,
+		GetSearch,
+		GetLint,
+		GetGen,
 	}
 	for _, provider := range providers {
 		c.Provide(provider)
@@ -262,6 +1178,24 @@ func NewApp(version string) *App {
 			Version:              version,
 			Usage:                "Utility for your CI/CD process to validate, register or delete Kafka protobuf schemes in the registry.",
 			EnableBashCompletion: true,
+			Before:               applyProfile,
+			Flags: []cli.Flag{
+				FlagConfig,
+				FlagProfile,
+				FlagTimeout,
+				FlagRetries,
+				FlagRetryBackoff,
+				FlagCacheTTL,
+				FlagCacheFile,
+				FlagTopicOption,
+				FlagRecordOption,
+				FlagFileTopicOption,
+				FlagFileRecordOption,
+				FlagTopicOptionNumber,
+				FlagRecordOptionNumber,
+				FlagOutputFormat,
+				FlagTemplate,
+			},
 		},
 		c: c,
 	}
@@ -278,6 +1212,8 @@ func NewApp(version string) *App {
 				FlagTopic,
 				FlagRecord,
 				FlagProtoRequired,
+				FlagReport,
+				FlagOutput,
 			},
 		},
 		{
@@ -286,11 +1222,46 @@ func NewApp(version string) *App {
 			ArgsUsage: "Set the topic, record and version of the schema to delete.",
 			Action:    makeAction(app, (*cmd.Delete)(nil)),
 			Flags: []cli.Flag{
+				FlagCluster,
 				FlagSRRequired,
 				FlagTopicRequired,
 				FlagRecord,
 				FlagVersion,
 				FlagPermanent,
+				FlagForce,
+				FlagScanWindow,
+				FlagYes,
+				FlagProtectedSubjects,
+			},
+		},
+		{
+			Name:      cmdUndelete,
+			Usage:     "Restores the soft-deleted version of the schema reusing its schema ID.",
+			ArgsUsage: "Set the topic, record and version of the schema to restore.",
+			Action:    makeAction(app, (*cmd.Undelete)(nil)),
+			Flags: []cli.Flag{
+				FlagSRRequired,
+				FlagTopicRequired,
+				FlagRecord,
+				FlagVersion,
+			},
+		},
+		{
+			Name:      cmdPrune,
+			Usage:     "Deletes the old versions of the topic subjects keeping the latest ones.",
+			ArgsUsage: "Set the topic, optionally the record, and the number of versions to keep or the age of the versions to delete.",
+			Action:    makeAction(app, (*cmd.Prune)(nil)),
+			Flags: []cli.Flag{
+				FlagCluster,
+				FlagSRRequired,
+				FlagTopicRequired,
+				FlagRecordOptional,
+				FlagKeep,
+				FlagOlderThan,
+				FlagSchemasTopic,
+				FlagPermanent,
+				FlagYes,
+				FlagProtectedSubjects,
 			},
 		},
 		{
@@ -302,7 +1273,35 @@ func NewApp(version string) *App {
 				FlagSRRequired,
 				FlagTopic,
 				FlagRecord,
+				FlagProto,
+				FlagChangedSince,
+				FlagLint,
+				FlagLintConfig,
+				FlagPolicy,
+				FlagReport,
+				FlagOutput,
+				FlagSample,
+			},
+		},
+		{
+			Name:   cmdLint,
+			Usage:  "Checks the proto files against the naming, field number, comment and type rules configured per directory.",
+			Action: makeAction(app, (*cmd.Lint)(nil)),
+			Flags: []cli.Flag{
+				FlagProtoRequired,
+				FlagLintConfig,
+				FlagReport,
+				FlagOutput,
+			},
+		},
+		{
+			Name:   cmdGen,
+			Usage:  "Generates Go constants of the topic, record and subject and the TopicRecord method for the messages with the (topic) option.",
+			Action: makeAction(app, (*cmd.Gen)(nil)),
+			Flags: []cli.Flag{
 				FlagProtoRequired,
+				FlagGoPackage,
+				FlagOutput,
 			},
 		},
 		{
@@ -313,6 +1312,7 @@ func NewApp(version string) *App {
 				FlagSRRequired,
 				FlagTopicRequired,
 				FlagRecord,
+				FlagIncludeDeleted,
 			},
 		},
 		{
@@ -324,15 +1324,17 @@ func NewApp(version string) *App {
 				FlagTopicRequired,
 				FlagRecord,
 				FlagVersion,
+				FlagIncludeDeleted,
 			},
 		},
 		{
 			Name:   cmdSubjects,
-			Usage:  "Lists available records for the topic.",
+			Usage:  "Lists available records for the topic or for all topics.",
 			Action: makeAction(app, (*cmd.Subjects)(nil)),
 			Flags: []cli.Flag{
 				FlagSRRequired,
-				FlagTopicRequired,
+				FlagTopic,
+				FlagIncludeDeleted,
 			},
 		},
 		{
@@ -344,9 +1346,94 @@ func NewApp(version string) *App {
 				FlagTopicRequired,
 				FlagRecord,
 				FlagVersion,
+				FlagExportFormat,
+				FlagSourceInfo,
 				FlagOutputRequired,
 			},
 		},
+		{
+			Name:   cmdProduce,
+			Usage:  "Publishes the message in JSON to the topic with the registered schema in the Confluent wire format.",
+			Action: makeAction(app, (*cmd.Produce)(nil)),
+			Flags: []cli.Flag{
+				FlagClusterRequired,
+				FlagSRRequired,
+				FlagTopicRequired,
+				FlagRecord,
+				FlagVersion,
+				FlagJSONRequired,
+				FlagKey,
+			},
+		},
+		{
+			Name:   cmdConsume,
+			Usage:  "Reads the messages of the topic and decodes them with the registered schemas from the payloads.",
+			Action: makeAction(app, (*cmd.Consume)(nil)),
+			Flags: []cli.Flag{
+				FlagClusterRequired,
+				FlagSRRequired,
+				FlagTopicRequired,
+				FlagFromOffset,
+				FlagLast,
+			},
+		},
+		{
+			Name:      cmdID,
+			Usage:     "Outputs the schema by its ID with every subject version using it.",
+			ArgsUsage: "<schema ID>",
+			Action:    makeAction(app, (*cmd.SchemaID)(nil)),
+			Flags: []cli.Flag{
+				FlagSRRequired,
+			},
+		},
+		{
+			Name:   cmdGraph,
+			Usage:  "Outputs the graph of the schema references from the registry or of the imports of the local proto files.",
+			Action: makeAction(app, (*cmd.Graph)(nil)),
+			Flags: []cli.Flag{
+				FlagSR,
+				FlagProtoPath,
+				FlagGraphFormat,
+				FlagDependsOn,
+				FlagPullsIn,
+				FlagOutput,
+			},
+		},
+		{
+			Name:   cmdSearch,
+			Usage:  "Finds the subjects across all topics by the topic and record globs or by the schema content.",
+			Action: makeAction(app, (*cmd.Search)(nil)),
+			Flags: []cli.Flag{
+				FlagSRRequired,
+				FlagTopicGlob,
+				FlagRecordGlob,
+				FlagContains,
+			},
+		},
+	}
+
+	// The connection settings are accepted by every command connecting to the registry or the cluster.
+	for _, command := range app.cliApp.Commands {
+		var sr, cluster bool
+		for _, flag := range command.Flags {
+			sr = sr || flag == FlagSR || flag == FlagSRRequired
+			cluster = cluster || flag == FlagClusterRequired
+		}
+		if sr {
+			command.Flags = append(command.Flags, FlagSRUsername, FlagSRPassword)
+		}
+		if cluster {
+			command.Flags = append(command.Flags,
+				FlagClusterTLS,
+				FlagClusterCAFile,
+				FlagClusterCertFile,
+				FlagClusterKeyFile,
+				FlagClusterTLSInsecure,
+				FlagClusterSASLMechanism,
+				FlagClusterSASLUsername,
+				FlagClusterSASLPassword,
+			)
+		}
 	}
 
 	return app
@@ -365,35 +1452,59 @@ type Runnable interface {
 
 func makeAction[T Runnable](a *App, _ T) cli.ActionFunc {
 	return func(c *cli.Context) error {
+		if timeout := c.Duration(FlagTimeout.Name); timeout > 0 {
+			ctx, cancel := context.WithTimeout(c.Context, timeout)
+			defer cancel()
+			c.Context = ctx
+		}
 		a.c.Provide(func() *cli.Context {
 			return c
 		})
 		return a.c.Invoke(func(runnable T) error {
+			// The result is written even with the error, e.g. the report of the failed validation.
 			result, err := runnable.Run(c.Context)
-			if err != nil {
+			if c.Duration(FlagCacheTTL.Name) > 0 {
+				if cacheErr := a.c.Invoke(saveRegistryCache); cacheErr != nil {
+					log.Printf("can not save registry cache: %v", cacheErr)
+				}
+			}
+			if result == nil {
 				return err
 			}
-			switch t := result.(type) {
-			case string:
-				log.Println(t)
-			case io.Reader:
-				outputFile := c.String("output")
-				f, err := os.Create(outputFile)
-				if err != nil {
-					return fmt.Errorf("can not create output file: %w", err)
-				}
-				defer f.Close()
-				if _, err := io.Copy(f, t); err != nil {
-					return fmt.Errorf("can not write output to the file: %w", err)
-				}
-			default:
-				output, err := json.MarshalIndent(result, "", "\t")
-				if err != nil {
-					return fmt.Errorf("can not marshall result: %w", err)
-				}
-				fmt.Println(string(output))
+			if writeErr := writeResult(c, result); writeErr != nil {
+				return writeErr
 			}
-			return nil
+			return err
 		})
 	}
 }
+
+func writeResult(c *cli.Context, result interface{}) error {
+	// The raw data is written as is, e.g. the exported schema.
+	if r, ok := result.(io.Reader); ok {
+		return writeOutput(c.String(FlagOutput.Name), r)
+	}
+	writer, err := output.NewWriter(os.Stdout, c.String(FlagOutputFormat.Name), c.String(FlagTemplate.Name))
+	if err != nil {
+		return err
+	}
+	return writer.Write(result)
+}
+
+func writeOutput(outputFile string, r io.Reader) error {
+	if outputFile == "" {
+		if _, err := io.Copy(os.Stdout, r); err != nil {
+			return fmt.Errorf("can not write output: %w", err)
+		}
+		return nil
+	}
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("can not create output file: %w", err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("can not write output to the file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Shopify/sarama"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// NamingTopicRecord is the only supported subject naming strategy, TopicRecordNameStrategy.
+const NamingTopicRecord = "topic_record"
+
+// Config is the config file with the named profiles. The profile values are used for the flags
+// set neither in the command line nor in the environment.
+type Config struct {
+	DefaultProfile string             `yaml:"default_profile" toml:"default_profile"`
+	Profiles       map[string]Profile `yaml:"profiles" toml:"profiles"`
+}
+
+// Profile holds the settings of the environment, e.g. stage. Defaults are the flag values by the flag names.
+type Profile struct {
+	Registry       RegistryConfig    `yaml:"registry" toml:"registry"`
+	Cluster        ClusterConfig     `yaml:"cluster" toml:"cluster"`
+	NamingStrategy string            `yaml:"naming_strategy" toml:"naming_strategy"`
+	Options        OptionsConfig     `yaml:"options" toml:"options"`
+	Defaults       map[string]string `yaml:"defaults" toml:"defaults"`
+}
+
+type RegistryConfig struct {
+	URL      string `yaml:"url" toml:"url"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+// OptionsConfig names the topic&record options of the schemas, e.g. kafka.topic and kafka.record.
+type OptionsConfig struct {
+	Topic        string `yaml:"topic" toml:"topic"`
+	Record       string `yaml:"record" toml:"record"`
+	FileTopic    string `yaml:"file_topic" toml:"file_topic"`
+	FileRecord   string `yaml:"file_record" toml:"file_record"`
+	TopicNumber  int    `yaml:"topic_number" toml:"topic_number"`
+	RecordNumber int    `yaml:"record_number" toml:"record_number"`
+}
+
+type ClusterConfig struct {
+	Brokers []string  `yaml:"brokers" toml:"brokers"`
+	TLS     TLSConfig `yaml:"tls" toml:"tls"`
+	SASL    SASL      `yaml:"sasl" toml:"sasl"`
+}
+
+type TLSConfig struct {
+	Enabled            bool   `yaml:"enabled" toml:"enabled"`
+	CAFile             string `yaml:"ca_file" toml:"ca_file"`
+	CertFile           string `yaml:"cert_file" toml:"cert_file"`
+	KeyFile            string `yaml:"key_file" toml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" toml:"insecure_skip_verify"`
+}
+
+type SASL struct {
+	Mechanism string `yaml:"mechanism" toml:"mechanism"`
+	Username  string `yaml:"username" toml:"username"`
+	Password  string `yaml:"password" toml:"password"`
+}
+
+// LoadConfig reads the config file. The file is TOML if it has the .toml extension and YAML otherwise.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can not read config: %w", err)
+	}
+	config := &Config{}
+	if filepath.Ext(path) == ".toml" {
+		err = toml.Unmarshal(data, config)
+	} else {
+		err = yaml.Unmarshal(data, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not parse config %q: %w", path, err)
+	}
+	return config, nil
+}
+
+// findConfig looks for .schema.yaml, .schema.yml or .schema.toml in the working directory and then for
+// config.yaml, config.yml or config.toml in the schema directory of the user config directory.
+func findConfig() string {
+	var candidates []string
+	for _, ext := range []string{".yaml", ".yml", ".toml"} {
+		candidates = append(candidates, ".schema"+ext)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		for _, ext := range []string{".yaml", ".yml", ".toml"} {
+			candidates = append(candidates, filepath.Join(dir, "schema", "config"+ext))
+		}
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Profile returns the named profile or the default one. The empty profile is returned if there is no default one.
+func (c *Config) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not found in config", name)
+	}
+	if profile.NamingStrategy != "" && profile.NamingStrategy != NamingTopicRecord {
+		return Profile{}, fmt.Errorf("profile %q: naming strategy %q is not supported, only %q is", name, profile.NamingStrategy, NamingTopicRecord)
+	}
+	return profile, nil
+}
+
+// flagValues returns the values of the profile by the flag names.
+func (p Profile) flagValues() map[string]string {
+	values := map[string]string{}
+	for name, value := range p.Defaults {
+		values[name] = value
+	}
+	set := func(name, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	setBool := func(name string, value bool) {
+		if value {
+			values[name] = strconv.FormatBool(value)
+		}
+	}
+	setInt := func(name string, value int) {
+		if value != 0 {
+			values[name] = strconv.Itoa(value)
+		}
+	}
+	set(FlagSR.Name, p.Registry.URL)
+	set(FlagSRUsername.Name, p.Registry.Username)
+	set(FlagSRPassword.Name, p.Registry.Password)
+	set(FlagClusterRequired.Name, strings.Join(p.Cluster.Brokers, ","))
+	setBool(FlagClusterTLS.Name, p.Cluster.TLS.Enabled)
+	set(FlagClusterCAFile.Name, p.Cluster.TLS.CAFile)
+	set(FlagClusterCertFile.Name, p.Cluster.TLS.CertFile)
+	set(FlagClusterKeyFile.Name, p.Cluster.TLS.KeyFile)
+	setBool(FlagClusterTLSInsecure.Name, p.Cluster.TLS.InsecureSkipVerify)
+	set(FlagClusterSASLMechanism.Name, p.Cluster.SASL.Mechanism)
+	set(FlagClusterSASLUsername.Name, p.Cluster.SASL.Username)
+	set(FlagClusterSASLPassword.Name, p.Cluster.SASL.Password)
+	set(FlagTopicOption.Name, p.Options.Topic)
+	set(FlagRecordOption.Name, p.Options.Record)
+	set(FlagFileTopicOption.Name, p.Options.FileTopic)
+	set(FlagFileRecordOption.Name, p.Options.FileRecord)
+	setInt(FlagTopicOptionNumber.Name, p.Options.TopicNumber)
+	setInt(FlagRecordOptionNumber.Name, p.Options.RecordNumber)
+	return values
+}
+
+// applyProfile loads the profile of the config file and applies its values with the lowest precedence:
+// the global flags not set are set, the environment variables of the command flags not set in the environment
+// are set, so the flags and the environment override the profile. The dotenv file of SCHEMA_CONFIG is loaded
+// to the environment before, so it overrides the profile too.
+func applyProfile(c *cli.Context) error {
+	path := c.String(FlagConfig.Name)
+	if path == "" {
+		path = findConfig()
+	}
+	profileName := c.String(FlagProfile.Name)
+	if path == "" {
+		if profileName != "" {
+			return errors.New("profile is set but config file is not found")
+		}
+		return nil
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	profile, err := config.Profile(profileName)
+	if err != nil {
+		return err
+	}
+
+	globalFlags := map[string]bool{}
+	for _, flag := range c.App.Flags {
+		for _, name := range flag.Names() {
+			globalFlags[name] = true
+		}
+	}
+	// The commands may declare the flags of the same name with different environment variables,
+	// e.g. format of graph and export, so the profile value is set for every one of them.
+	commandFlags := map[string][][]string{}
+	for _, command := range c.App.Commands {
+		for _, flag := range command.Flags {
+			f, ok := flag.(cli.DocGenerationFlag)
+			if !ok || len(f.GetEnvVars()) == 0 || containsEnvVars(commandFlags[flag.Names()[0]], f.GetEnvVars()) {
+				continue
+			}
+			commandFlags[flag.Names()[0]] = append(commandFlags[flag.Names()[0]], f.GetEnvVars())
+		}
+	}
+
+	values := profile.flagValues()
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := values[name]
+		if globalFlags[name] {
+			if !c.IsSet(name) {
+				if err := c.Set(name, value); err != nil {
+					return fmt.Errorf("can not set %q from profile: %w", name, err)
+				}
+			}
+			continue
+		}
+		flags, ok := commandFlags[name]
+		if !ok {
+			return fmt.Errorf("unknown flag %q in profile", name)
+		}
+		for _, envVars := range flags {
+			if envSet(envVars) {
+				continue
+			}
+			if err := os.Setenv(envVars[0], value); err != nil {
+				return fmt.Errorf("can not set %q from profile: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func containsEnvVars(flags [][]string, envVars []string) bool {
+	for _, flag := range flags {
+		if flag[0] == envVars[0] {
+			return true
+		}
+	}
+	return false
+}
+
+func envSet(envVars []string) bool {
+	for _, env := range envVars {
+		if _, ok := os.LookupEnv(env); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// apply configures TLS and SASL of the Kafka client.
+func (s ClusterSecurity) apply(config *sarama.Config) error {
+	if s.TLS {
+		tlsConfig := &tls.Config{InsecureSkipVerify: s.InsecureSkipVerify}
+		if s.CAFile != "" {
+			ca, err := os.ReadFile(s.CAFile)
+			if err != nil {
+				return fmt.Errorf("can not read CA file: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+				return fmt.Errorf("can not parse CA file %q", s.CAFile)
+			}
+		}
+		if s.CertFile != "" || s.KeyFile != "" {
+			certificate, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+			if err != nil {
+				return fmt.Errorf("can not load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	switch s.SASLMechanism {
+	case "":
+	case sarama.SASLTypePlaintext:
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		config.Net.SASL.User = s.SASLUsername
+		config.Net.SASL.Password = s.SASLPassword
+	default:
+		return fmt.Errorf("SASL mechanism %q is not supported", s.SASLMechanism)
+	}
+	return nil
+}
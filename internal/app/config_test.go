@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestApplyProfile(t *testing.T) {
+	envVars := []string{"GRAPH_FORMAT", "EXPORT_FORMAT", "SCAN_WINDOW", "DOTENV_WINDOW"}
+	for _, env := range envVars {
+		env := env
+		value, ok := os.LookupEnv(env)
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(env, value)
+			} else {
+				os.Unsetenv(env)
+			}
+		})
+		os.Unsetenv(env)
+	}
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(config, []byte(`default_profile: dev
+profiles:
+  dev:
+    defaults:
+      format: dot
+      scan-window: "5000"
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The variable set before the profile is applied, e.g. by the dotenv file, overrides the profile.
+	os.Setenv("DOTENV_WINDOW", "10")
+
+	app := &cli.App{
+		Flags:  []cli.Flag{FlagConfig, FlagProfile},
+		Before: applyProfile,
+		Commands: []*cli.Command{
+			{Name: "graph", Flags: []cli.Flag{&cli.StringFlag{Name: "format", EnvVars: []string{"GRAPH_FORMAT"}}}},
+			{Name: "export", Flags: []cli.Flag{&cli.StringFlag{Name: "format", EnvVars: []string{"EXPORT_FORMAT"}}}},
+			{Name: "delete", Flags: []cli.Flag{&cli.StringFlag{Name: "scan-window", EnvVars: []string{"DOTENV_WINDOW", "SCAN_WINDOW"}}}},
+		},
+		Action: func(*cli.Context) error { return nil },
+	}
+	if err := app.Run([]string{"schema", "--config", config}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := map[string]string{"GRAPH_FORMAT": "dot", "EXPORT_FORMAT": "dot", "SCAN_WINDOW": "", "DOTENV_WINDOW": "10"}
+	for env, value := range want {
+		if got := os.Getenv(env); got != value {
+			t.Errorf("%s = %q, want %q", env, got, value)
+		}
+	}
+}
+
+func TestApplyProfileUnknownFlag(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(config, []byte("default_profile = \"dev\"\n[profiles.dev.defaults]\nunknown = \"1\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	app := &cli.App{
+		Flags:  []cli.Flag{FlagConfig, FlagProfile},
+		Before: applyProfile,
+		Action: func(*cli.Context) error { return nil },
+	}
+	if err := app.Run([]string{"schema", "--config", config}); err == nil {
+		t.Fatal("Run() error = nil, want unknown flag error")
+	}
+}
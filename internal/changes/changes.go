@@ -0,0 +1,180 @@
+// Package changes finds the proto files changed in the local git repository.
+package changes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+// Changes lists the proto files affected by the changes since the git reference. The paths are relative
+// to the working directory.
+type Changes struct {
+	// Affected are the added or modified files and the files importing them directly or transitively.
+	// The deleted files are not affected.
+	Affected []string
+	// Deleted are the deleted files with their content at the reference.
+	Deleted []File
+}
+
+// File is the proto file content.
+type File struct {
+	Path   string
+	Schema []byte
+}
+
+// Find finds the changes of the proto files since the git reference, including the uncommitted and the untracked
+// files. Only the candidates are checked to be affected, all proto files of the working directory are
+// the candidates if none are given.
+func Find(ctx context.Context, ref string, candidates []string) (*Changes, error) {
+	// The changes of the whole repository are found, as the files may import the files outside the working directory.
+	prefix, err := git(ctx, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := git(ctx, "diff", "--name-status", "--no-renames", "-z", ref, "--", ":/*.proto")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(ctx, "ls-files", "-z", "--others", "--exclude-standard", "--full-name", "--", ":/*.proto")
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		files, err := git(ctx, "ls-files", "-z", "--cached", "--others", "--exclude-standard", "--", "*.proto")
+		if err != nil {
+			return nil, err
+		}
+		// The files deleted from the working tree but not from the index are listed too.
+		for _, file := range nulFields(files) {
+			if _, err := os.Stat(file); err == nil {
+				candidates = append(candidates, file)
+			}
+		}
+	}
+	relative := func(path string) (string, error) {
+		rel, err := filepath.Rel(filepath.FromSlash(strings.TrimSpace(string(prefix))+"."), filepath.FromSlash(path))
+		if err != nil {
+			return "", fmt.Errorf("can not make path %q relative: %w", path, err)
+		}
+		return filepath.ToSlash(rel), nil
+	}
+
+	changes := &Changes{}
+	changed := map[string]bool{}
+	// The status and the path are separated with NUL, so the paths are neither split nor quoted.
+	fields := nulFields(diff)
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, path := fields[i], fields[i+1]
+		rel, err := relative(path)
+		if err != nil {
+			return nil, err
+		}
+		if status != "D" {
+			changed[rel] = true
+			continue
+		}
+		schema, err := git(ctx, "show", fmt.Sprintf("%s:%s", ref, path))
+		if err != nil {
+			return nil, err
+		}
+		changes.Deleted = append(changes.Deleted, File{Path: rel, Schema: schema})
+	}
+	for _, path := range nulFields(untracked) {
+		rel, err := relative(path)
+		if err != nil {
+			return nil, err
+		}
+		changed[rel] = true
+	}
+
+	affected, err := affectedFiles(candidates, changed)
+	if err != nil {
+		return nil, err
+	}
+	changes.Affected = affected
+	return changes, nil
+}
+
+// affectedFiles selects the changed candidates and the candidates importing the changed or affected files.
+func affectedFiles(candidates []string, changed map[string]bool) ([]string, error) {
+	imports := make(map[string][]string, len(candidates))
+	affected := map[string]bool{}
+	for _, candidate := range candidates {
+		path := filepath.ToSlash(filepath.Clean(candidate))
+		if changed[path] {
+			affected[candidate] = true
+			continue
+		}
+		protobuf, err := os.ReadFile(candidate)
+		if err != nil {
+			return nil, fmt.Errorf("error reading schema: %w", err)
+		}
+		imports[candidate], err = protoschema.Imports(protobuf)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", candidate, err)
+		}
+	}
+
+	// The affected files are the changed ones too for the files importing them.
+	for found := true; found; {
+		found = false
+		for candidate, candidateImports := range imports {
+			if affected[candidate] || !importsAny(candidateImports, changed) {
+				continue
+			}
+			affected[candidate] = true
+			changed[filepath.ToSlash(filepath.Clean(candidate))] = true
+			found = true
+		}
+	}
+
+	result := make([]string, 0, len(affected))
+	for candidate := range affected {
+		result = append(result, candidate)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// importsAny checks the imports to match any of the paths. The import matches the path with the same suffix,
+// as the import path is relative to one of the import directories.
+func importsAny(imports []string, paths map[string]bool) bool {
+	for _, i := range imports {
+		for path := range paths {
+			if path == i || strings.HasSuffix(path, "/"+i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func git(ctx context.Context, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	command := exec.CommandContext(ctx, "git", args...)
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		return nil, fmt.Errorf("can not run git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// nulFields splits the output of the git command run with -z.
+func nulFields(output []byte) []string {
+	var result []string
+	for _, field := range strings.Split(string(output), "\x00") {
+		if field != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package changes
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newRepo creates the git repository in the temporary directory and changes the working directory to it.
+func newRepo(t *testing.T) (run func(args ...string), write func(name, content string)) {
+	t.Helper()
+	dir := t.TempDir()
+	run = func(args ...string) {
+		t.Helper()
+		command := exec.Command("git", args...)
+		command.Dir = dir
+		command.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if output, err := command.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	write = func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	run("init", "-q")
+	return run, write
+}
+
+func TestFind(t *testing.T) {
+	run, write := newRepo(t)
+	write("base types.proto", `syntax = "proto3"; message Base {}`)
+	write("user.proto", `syntax = "proto3"; import "base types.proto"; message User {}`)
+	write("погода.proto", `syntax = "proto3"; message Weather {}`)
+	write("stable.proto", `syntax = "proto3"; message Stable {}`)
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+
+	write("base types.proto", `syntax = "proto3"; message Base { string id = 1; }`)
+	write("new file.proto", `syntax = "proto3"; message New {}`)
+	run("rm", "-q", "погода.proto")
+
+	changes, err := Find(context.Background(), "HEAD", nil)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	wantAffected := []string{"base types.proto", "new file.proto", "user.proto"}
+	if !reflect.DeepEqual(changes.Affected, wantAffected) {
+		t.Errorf("Find() affected = %q, want %q", changes.Affected, wantAffected)
+	}
+	if len(changes.Deleted) != 1 || changes.Deleted[0].Path != "погода.proto" ||
+		string(changes.Deleted[0].Schema) != `syntax = "proto3"; message Weather {}` {
+		t.Errorf("Find() deleted = %+v, want погода.proto", changes.Deleted)
+	}
+}
+
+func TestFindUnstagedDeletion(t *testing.T) {
+	run, write := newRepo(t)
+	write("base.proto", `syntax = "proto3"; message Base {}`)
+	write("user.proto", `syntax = "proto3"; message User {}`)
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+
+	// The file is still in the index, so git ls-files --cached lists it.
+	if err := os.Remove("base.proto"); err != nil {
+		t.Fatal(err)
+	}
+	write("user.proto", `syntax = "proto3"; message User { string id = 1; }`)
+
+	changes, err := Find(context.Background(), "HEAD", nil)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if want := []string{"user.proto"}; !reflect.DeepEqual(changes.Affected, want) {
+		t.Errorf("Find() affected = %q, want %q", changes.Affected, want)
+	}
+	if len(changes.Deleted) != 1 || changes.Deleted[0].Path != "base.proto" {
+		t.Errorf("Find() deleted = %+v, want base.proto", changes.Deleted)
+	}
+}
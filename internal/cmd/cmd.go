@@ -1,5 +0,0 @@
-package cmd
-
-func subjectName(kafkaTopic, record string) string {
-	return kafkaTopic + "-" + record + "-value"
-}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/youla-dev/schema/lib/schema"
+)
+
+type Consume struct {
+	client  *schema.Client
+	request schema.ConsumeRequest
+}
+
+func NewConsume(client *schema.Client, request schema.ConsumeRequest) (*Consume, error) {
+	return &Consume{
+		client:  client,
+		request: request,
+	}, nil
+}
+
+func (c *Consume) Run(ctx context.Context) (interface{}, error) {
+	response, err := c.client.Consume(ctx, c.request)
+	if err != nil {
+		return nil, err
+	}
+	return response.Messages, nil
+}
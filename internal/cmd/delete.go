@@ -1,42 +1,61 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
 
-	"github.com/riferrei/srclient"
+	"github.com/youla-dev/schema/lib/schema"
 )
 
 type Delete struct {
-	schemaRegistryClient srclient.ISchemaRegistryClient
-	topic, record        string
-	version              int
-	permanent            bool
+	client  *schema.Client
+	request schema.DeleteRequest
+	yes     bool
 }
 
-func NewDelete(
-	schemaRegistryClient srclient.ISchemaRegistryClient,
-	topic, record string,
-	version int,
-	permanent bool,
-) (*Delete, error) {
+func NewDelete(client *schema.Client, request schema.DeleteRequest, yes bool) (*Delete, error) {
 	return &Delete{
-		schemaRegistryClient: schemaRegistryClient,
-		topic:                topic,
-		record:               record,
-		version:              version,
-		permanent:            permanent,
+		client:  client,
+		request: request,
+		yes:     yes,
 	}, nil
 }
 
 func (d *Delete) Run(c context.Context) (interface{}, error) {
-	subject := subjectName(d.topic, d.record)
-	var err error
+	plan, err := d.client.PlanDelete(c, d.request)
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.yes {
+		confirmed, err := confirm(plan.String())
+		if err != nil {
+			return nil, err
+		}
+		if !confirmed {
+			return nil, errors.New("deletion is cancelled")
+		}
+	}
 
-	if d.version == 0 {
-		err = d.schemaRegistryClient.DeleteSubject(subject, d.permanent)
-	} else {
-		err = d.schemaRegistryClient.DeleteSubjectByVersion(subject, d.version, d.permanent)
+	return d.client.ApplyDelete(c, plan)
+}
+
+// confirm asks the user to confirm the action in the terminal.
+func confirm(action string) (bool, error) {
+	stat, err := os.Stdin.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return false, fmt.Errorf("confirmation is required, use --yes in non-interactive mode:\n%s", action)
 	}
 
-	return nil, err
+	fmt.Fprintf(os.Stderr, "%s\nContinue? [y/N]: ", action)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("can not read confirmation: %w", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
 }
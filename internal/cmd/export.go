@@ -3,56 +3,49 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 
-	"github.com/riferrei/srclient"
+	"github.com/youla-dev/schema/lib/schema"
+)
+
+const (
+	ExportFormatProto         = "proto"
+	ExportFormatDescriptorSet = "descriptor-set"
 )
 
 type Export struct {
-	schemaRegistryClient srclient.ISchemaRegistryClient
-	topic, record        string
-	version              int
+	client  *schema.Client
+	request schema.ExportRequest
 }
 
-func NewExport(
-	schemaRegistryClient srclient.ISchemaRegistryClient,
-	topic, record string,
-	version int,
-) (*Export, error) {
+func NewExport(client *schema.Client, request schema.ExportRequest, format string) (*Export, error) {
+	switch format {
+	case ExportFormatProto:
+	case ExportFormatDescriptorSet:
+		request.DescriptorSet = true
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
 	return &Export{
-		schemaRegistryClient: schemaRegistryClient,
-		topic:                topic,
-		record:               record,
-		version:              version,
+		client:  client,
+		request: request,
 	}, nil
 }
 
 func (e *Export) Run(c context.Context) (interface{}, error) {
-	validatingSubject := subjectName(e.topic, e.record)
-
-	subjects, err := e.schemaRegistryClient.GetSubjects()
-	if err != nil {
-		return nil, fmt.Errorf("can not get subjects: %w", err)
-	}
-	var subjectExist bool
-	for _, subject := range subjects {
-		subjectExist = subjectExist || subject == validatingSubject
-	}
-
-	if !subjectExist {
-		fmt.Println("schema not exist yet")
+	response, err := e.client.Export(c, e.request)
+	if errors.Is(err, schema.ErrSubjectNotExist) {
+		fmt.Fprintln(os.Stderr, err)
 		return nil, nil
 	}
-
-	var schema *srclient.Schema
-	if e.version == 0 {
-		schema, err = e.schemaRegistryClient.GetLatestSchema(validatingSubject)
-	} else {
-		schema, err = e.schemaRegistryClient.GetSchemaByVersion(validatingSubject, e.version)
-	}
 	if err != nil {
-		return nil, fmt.Errorf("error schema: %w", err)
+		return nil, err
 	}
 
-	return bytes.NewBufferString(schema.Schema()), nil
+	if e.request.DescriptorSet {
+		return bytes.NewReader(response.DescriptorSet), nil
+	}
+	return bytes.NewBufferString(response.Schema), nil
 }
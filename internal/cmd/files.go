@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/youla-dev/schema/internal/report"
+	"github.com/youla-dev/schema/lib/protoschema"
+	"github.com/youla-dev/schema/lib/schema"
+)
+
+// ProtoFile is the schema read from the file.
+type ProtoFile struct {
+	Path   string
+	Schema []byte
+}
+
+type fileResult struct {
+	File   string      `json:"file"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Orphan string      `json:"orphan_subject,omitempty"`
+}
+
+// newCase creates the case of the file. The subject and the record are taken from the options of the schema
+// if the subject is unknown, e.g. the operation failed.
+func newCase(
+	ctx context.Context,
+	options protoschema.Options,
+	file ProtoFile,
+	subject, record, outcome, message string,
+) report.Case {
+	if subject == "" {
+		subject = file.Path
+		if topic, optionRecord, err := options.Parse(ctx, file.Schema); err == nil && topic != "" {
+			subject = schema.SubjectName(topic, optionRecord)
+			record = optionRecord
+		}
+	}
+	position := options.Locate(file.Schema, record)
+	return report.Case{
+		File:    file.Path,
+		Line:    position.Line,
+		Column:  position.Column,
+		Subject: subject,
+		Outcome: outcome,
+		Message: message,
+	}
+}
+
+// filesOutput returns the report in the format or the results of the files. The error is returned
+// together with the output if any of the files failed.
+func filesOutput(format string, r *report.Report, results interface{}) (interface{}, error) {
+	var err error
+	if failed := r.Failed(); failed > 0 {
+		err = fmt.Errorf("%s failed for %d of %d cases", r.Command, failed, len(r.Cases))
+	}
+	if format == "" {
+		return results, err
+	}
+	output, renderErr := report.Render(format, r)
+	if renderErr != nil {
+		return nil, renderErr
+	}
+	return bytes.NewBuffer(output), err
+}
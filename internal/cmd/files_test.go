@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/youla-dev/schema/internal/report"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+const weatherProto = `syntax = "proto3";
+package weather;
+
+import "google/protobuf/descriptor.proto";
+
+extend google.protobuf.MessageOptions {
+  string topic = 50001;
+  string record = 50002;
+}
+
+message Weather {
+  option (topic) = "weather";
+  option (record) = "Weather";
+  string city = 1;
+}
+`
+
+func TestNewCase(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    ProtoFile
+		subject string
+		record  string
+		want    report.Case
+	}{
+		{
+			name:    "subject of response",
+			file:    ProtoFile{Path: "proto/weather.proto", Schema: []byte(weatherProto)},
+			subject: "weather-Weather-value",
+			record:  "Weather",
+			want:    report.Case{File: "proto/weather.proto", Line: 13, Column: 3, Subject: "weather-Weather-value", Outcome: report.Failed, Message: "failed"},
+		},
+		{
+			name: "subject of options",
+			file: ProtoFile{Path: "proto/weather.proto", Schema: []byte(weatherProto)},
+			want: report.Case{File: "proto/weather.proto", Line: 13, Column: 3, Subject: "weather-Weather-value", Outcome: report.Failed, Message: "failed"},
+		},
+		{
+			name: "file without options",
+			file: ProtoFile{Path: "proto/plain file.proto", Schema: []byte("syntax = \"proto3\";\nmessage Plain {}\n")},
+			want: report.Case{File: "proto/plain file.proto", Line: 2, Column: 1, Subject: "proto/plain file.proto", Outcome: report.Failed, Message: "failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newCase(context.Background(), protoschema.DefaultOptions, tt.file, tt.subject, tt.record, report.Failed, "failed")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("newCase() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilesOutput(t *testing.T) {
+	passed := report.Case{File: "weather.proto", Line: 11, Column: 1, Subject: "weather-Weather-value", Outcome: report.Passed, Message: "schema is compatible"}
+	failed := report.Case{File: "orders, v2.proto", Line: 3, Column: 1, Subject: "orders-Order-value", Outcome: report.Failed, Message: "error validating schema:\nincompatible"}
+	results := []fileResult{{File: "weather.proto"}, {File: "orders, v2.proto", Error: "error validating schema"}}
+
+	tests := []struct {
+		name    string
+		format  string
+		cases   []report.Case
+		want    interface{}
+		wantErr string
+	}{
+		{name: "results", cases: []report.Case{passed}, want: results},
+		{name: "failed results", cases: []report.Case{passed, failed}, want: results, wantErr: "validate failed for 1 of 2 cases"},
+		{
+			name:    "report",
+			format:  report.FormatGitHub,
+			cases:   []report.Case{passed, failed},
+			want:    bytes.NewBufferString("::error file=orders%2C v2.proto,line=3,col=1,title=orders-Order-value::error validating schema:%0Aincompatible\n"),
+			wantErr: "validate failed for 1 of 2 cases",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filesOutput(tt.format, &report.Report{Command: "validate", Cases: tt.cases}, results)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("filesOutput() error = %v, want %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("filesOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/youla-dev/schema/lib/gen"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+type Gen struct {
+	files     []ProtoFile
+	goPackage string
+	options   protoschema.Options
+}
+
+func NewGen(files []ProtoFile, goPackage string, options protoschema.Options) (*Gen, error) {
+	return &Gen{
+		files:     files,
+		goPackage: goPackage,
+		options:   options,
+	}, nil
+}
+
+func (g *Gen) Run(c context.Context) (interface{}, error) {
+	files := make([]*gen.File, 0, len(g.files))
+	for _, file := range g.files {
+		parsed, err := gen.Parse(file.Path, file.Schema, g.options)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, parsed)
+	}
+	code, err := gen.Generate(files, g.goPackage)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(code), nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/youla-dev/schema/lib/schema"
+)
+
+const (
+	GraphFormatDOT     = "dot"
+	GraphFormatMermaid = "mermaid"
+	GraphFormatJSON    = "json"
+)
+
+type Graph struct {
+	client  *schema.Client
+	request schema.GraphRequest
+	format  string
+}
+
+func NewGraph(client *schema.Client, request schema.GraphRequest, format string) (*Graph, error) {
+	switch format {
+	case GraphFormatDOT, GraphFormatMermaid, GraphFormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown graph format %q", format)
+	}
+	return &Graph{
+		client:  client,
+		request: request,
+		format:  format,
+	}, nil
+}
+
+func (g *Graph) Run(c context.Context) (interface{}, error) {
+	graph, err := g.client.Graph(c, g.request)
+	if err != nil {
+		return nil, err
+	}
+	for _, cycle := range graph.Cycles {
+		log.Printf("cycle of references: %s", strings.Join(cycle, ", "))
+	}
+	for _, edge := range graph.Dangling {
+		log.Printf("dangling reference %q from %q to %q", edge.Name, edge.From, edge.To)
+	}
+
+	switch g.format {
+	case GraphFormatDOT:
+		return bytes.NewBufferString(graph.DOT()), nil
+	case GraphFormatMermaid:
+		return bytes.NewBufferString(graph.Mermaid()), nil
+	default:
+		return graph, nil
+	}
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/youla-dev/schema/lib/schema"
+)
+
+type SchemaID struct {
+	client  *schema.Client
+	request schema.SchemaByIDRequest
+}
+
+func NewSchemaID(client *schema.Client, request schema.SchemaByIDRequest) (*SchemaID, error) {
+	return &SchemaID{
+		client:  client,
+		request: request,
+	}, nil
+}
+
+func (s *SchemaID) Run(c context.Context) (interface{}, error) {
+	return s.client.SchemaByID(c, s.request)
+}
@@ -2,72 +2,57 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/riferrei/srclient"
+	"github.com/youla-dev/schema/lib/schema"
 )
 
 type Inspect struct {
-	schemaRegistryClient srclient.ISchemaRegistryClient
-	topic, record        string
-	version              int
+	client  *schema.Client
+	request schema.InspectRequest
 }
 
-func NewInspect(schemaRegistryClient srclient.ISchemaRegistryClient, topic, record string, version int) (*Inspect, error) {
+func NewInspect(client *schema.Client, request schema.InspectRequest) (*Inspect, error) {
 	return &Inspect{
-		schemaRegistryClient: schemaRegistryClient,
-		topic:                topic,
-		record:               record,
-		version:              version,
+		client:  client,
+		request: request,
 	}, nil
 }
 
 type inspectOutput struct {
-	Subject    string `json:"subject"`
-	ID         int    `json:"id"`
-	Version    int    `json:"version"`
-	References string `json:"references"`
-	Schema     string `json:"schema"`
+	Subject    string               `json:"subject"`
+	ID         int                  `json:"id"`
+	Version    int                  `json:"version"`
+	References []srclient.Reference `json:"references"`
+	Schema     string               `json:"schema"`
+	Deleted    bool                 `json:"deleted,omitempty"`
 }
 
 func (i *Inspect) Run(c context.Context) (interface{}, error) {
-	validatingSubject := subjectName(i.topic, i.record)
-
-	subjects, err := i.schemaRegistryClient.GetSubjects()
-	if err != nil {
-		return nil, fmt.Errorf("can not get subjects: %w", err)
-	}
-	var subjectExist bool
-	for _, subject := range subjects {
-		subjectExist = subjectExist || subject == validatingSubject
-	}
-
-	if !subjectExist {
-		return "schema not exist yet", nil
-	}
-
-	var schema *srclient.Schema
-	if i.version == 0 {
-		schema, err = i.schemaRegistryClient.GetLatestSchema(validatingSubject)
-	} else {
-		schema, err = i.schemaRegistryClient.GetSchemaByVersion(validatingSubject, i.version)
+	response, err := i.client.Inspect(c, i.request)
+	if errors.Is(err, schema.ErrSubjectNotExist) {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("error schema: %w", err)
+		return nil, err
 	}
 
-	references, err := json.Marshal(schema.References())
-	if err != nil {
-		return nil, fmt.Errorf("can not marshal references: %w", err)
+	references := response.References
+	if references == nil {
+		references = []srclient.Reference{}
 	}
 
 	output := inspectOutput{
-		Subject:    validatingSubject,
-		ID:         schema.ID(),
-		Version:    schema.Version(),
-		References: string(references),
-		Schema:     schema.Schema(),
+		Subject:    response.Subject,
+		ID:         response.ID,
+		Version:    response.Version,
+		References: references,
+		Schema:     response.Schema,
+		Deleted:    response.Deleted,
 	}
 
 	return output, nil
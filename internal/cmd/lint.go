@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/youla-dev/schema/internal/report"
+	"github.com/youla-dev/schema/lib/lint"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+type Lint struct {
+	files      []ProtoFile
+	configPath string
+	report     string
+	options    protoschema.Options
+}
+
+func NewLint(files []ProtoFile, configPath, reportFormat string, options protoschema.Options) (*Lint, error) {
+	if err := report.Validate(reportFormat); err != nil {
+		return nil, err
+	}
+	return &Lint{
+		files:      files,
+		configPath: configPath,
+		report:     reportFormat,
+		options:    options,
+	}, nil
+}
+
+type lintOutput struct {
+	File string `json:"file"`
+	lint.Finding
+}
+
+func (l *Lint) Run(c context.Context) (interface{}, error) {
+	rep := &report.Report{Command: "lint"}
+	output := []lintOutput{}
+	for _, file := range l.files {
+		config, err := l.config(file)
+		if err != nil {
+			return nil, err
+		}
+		findings, err := lint.Lint(file.Schema, config, l.options)
+		if err != nil {
+			rep.Cases = append(rep.Cases, newCase(c, l.options, file, "", "", report.Failed, err.Error()))
+			output = append(output, lintOutput{File: file.Path, Finding: lint.Finding{Line: 1, Column: 1, Message: err.Error()}})
+			continue
+		}
+		if len(findings) == 0 {
+			rep.Cases = append(rep.Cases, report.Case{File: file.Path, Line: 1, Column: 1, Subject: file.Path, Outcome: report.Passed})
+		}
+		for _, finding := range findings {
+			rep.Cases = append(rep.Cases, report.Case{
+				File:    file.Path,
+				Line:    finding.Line,
+				Column:  finding.Column,
+				Subject: fmt.Sprintf("%s: %s", file.Path, finding.Rule),
+				Outcome: report.Failed,
+				Message: finding.Message,
+			})
+			output = append(output, lintOutput{File: file.Path, Finding: finding})
+		}
+	}
+	return filesOutput(l.report, rep, output)
+}
+
+func (l *Lint) config(file ProtoFile) (lint.Config, error) {
+	if l.configPath != "" {
+		return lint.LoadConfig(l.configPath)
+	}
+	return lint.ConfigFor(file.Path)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/youla-dev/schema/lib/schema"
+)
+
+type Produce struct {
+	client  *schema.Client
+	request schema.ProduceRequest
+}
+
+func NewProduce(client *schema.Client, request schema.ProduceRequest) (*Produce, error) {
+	return &Produce{
+		client:  client,
+		request: request,
+	}, nil
+}
+
+func (p *Produce) Run(c context.Context) (interface{}, error) {
+	return p.client.Produce(c, p.request)
+}
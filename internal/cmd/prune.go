@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/youla-dev/schema/lib/schema"
+)
+
+type Prune struct {
+	client  *schema.Client
+	request schema.PruneRequest
+	yes     bool
+}
+
+func NewPrune(client *schema.Client, request schema.PruneRequest, yes bool) (*Prune, error) {
+	return &Prune{
+		client:  client,
+		request: request,
+		yes:     yes,
+	}, nil
+}
+
+func (p *Prune) Run(c context.Context) (interface{}, error) {
+	plan, err := p.client.PlanPrune(c, p.request)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Deletions) == 0 {
+		return plan, nil
+	}
+
+	if !p.yes {
+		confirmed, err := confirm(plan.String())
+		if err != nil {
+			return nil, err
+		}
+		if !confirmed {
+			return nil, errors.New("prune is cancelled")
+		}
+	}
+
+	return p.client.ApplyPrune(c, plan)
+}
@@ -2,81 +2,55 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 
-	saramaCluster "github.com/bsm/sarama-cluster"
-	"github.com/riferrei/srclient"
-	"github.com/youla-dev/schema/lib/protoschema"
+	"github.com/youla-dev/schema/internal/report"
+	"github.com/youla-dev/schema/lib/schema"
 )
 
 type Register struct {
-	schemaRegistryClient srclient.ISchemaRegistryClient
-	clusterClient        *saramaCluster.Client
-	topic, record        string
-	schemaBytes          []byte
+	client  *schema.Client
+	request schema.RegisterRequest
+	files   []ProtoFile
+	report  string
 }
 
-func NewRegister(
-	schemaRegistryClient srclient.ISchemaRegistryClient,
-	clusterClient *saramaCluster.Client,
-	topic, record string,
-	schemaBytes []byte,
-) (*Register, error) {
+func NewRegister(client *schema.Client, request schema.RegisterRequest, files []ProtoFile, reportFormat string) (*Register, error) {
+	if err := report.Validate(reportFormat); err != nil {
+		return nil, err
+	}
 	return &Register{
-		schemaRegistryClient: schemaRegistryClient,
-		clusterClient:        clusterClient,
-		topic:                topic,
-		record:               record,
-		schemaBytes:          schemaBytes,
+		client:  client,
+		request: request,
+		files:   files,
+		report:  reportFormat,
 	}, nil
 }
 
 func (r *Register) Run(ctx context.Context) (interface{}, error) {
-	t := srclient.Protobuf
-	var err error
-	if r.topic == "" || r.record == "" {
-		r.topic, r.record, err = protoschema.Parse(context.Background(), r.schemaBytes)
+	if len(r.files) == 1 && r.report == "" {
+		request := r.request
+		request.Schema = r.files[0].Schema
+		return r.client.Register(ctx, request)
+	}
+
+	rep := &report.Report{Command: "register"}
+	results := make([]fileResult, 0, len(r.files))
+	for _, file := range r.files {
+		request := r.request
+		request.Schema = file.Schema
+		response, err := r.client.Register(ctx, request)
 		if err != nil {
-			return nil, fmt.Errorf("can not extract topic and record from proto: %w", err)
+			rep.Cases = append(rep.Cases, newCase(ctx, r.client.Options(), file, "", request.Record, report.Failed, err.Error()))
+			results = append(results, fileResult{File: file.Path, Error: err.Error()})
+			continue
 		}
-	}
-
-	if r.topic == "" {
-		return nil, fmt.Errorf("topic %q is invalid", r.topic)
-	}
 
-	validatingSubject := subjectName(r.topic, r.record)
-
-	// Topic search
-	topics, err := r.clusterClient.Topics()
-	if err != nil {
-		return nil, fmt.Errorf("can not list topics: %w", err)
-	}
-	var topicExists bool
-	for _, topic := range topics {
-		if r.topic == topic {
-			topicExists = true
-			break
+		outcome := report.Passed
+		if response.Status == schema.StatusTopicNotExist {
+			outcome = report.Skipped
 		}
+		rep.Cases = append(rep.Cases, newCase(ctx, r.client.Options(), file, response.Subject, response.Record, outcome, response.String()))
+		results = append(results, fileResult{File: file.Path, Result: response})
 	}
-	if !topicExists {
-		return fmt.Sprintf("topic %q not exist", r.topic), nil
-	}
-
-	// Does the topic exist
-	subjects, err := r.schemaRegistryClient.GetSubjects()
-	if err != nil {
-		return nil, fmt.Errorf("can not get subjects: %w", err)
-	}
-	var subjectExist bool
-	for _, subject := range subjects {
-		subjectExist = subjectExist || subject == validatingSubject
-	}
-
-	schema, err := r.schemaRegistryClient.CreateSchema(validatingSubject, string(r.schemaBytes), t)
-	if err != nil {
-		return nil, fmt.Errorf("error creating the schema %w", err)
-	}
-
-	return fmt.Sprintln("Created schema ID", schema.ID(), ", version", schema.Version()), nil
+	return filesOutput(r.report, rep, results)
 }
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/youla-dev/schema/lib/schema"
+)
+
+type Search struct {
+	client  *schema.Client
+	request schema.SearchRequest
+}
+
+func NewSearch(client *schema.Client, request schema.SearchRequest) (*Search, error) {
+	return &Search{
+		client:  client,
+		request: request,
+	}, nil
+}
+
+func (s *Search) Run(c context.Context) (interface{}, error) {
+	return s.client.Search(c, s.request)
+}
@@ -2,44 +2,46 @@ package cmd
 
 import (
 	"context"
-	"fmt"
-	"regexp"
 
-	"github.com/riferrei/srclient"
+	"github.com/youla-dev/schema/lib/schema"
 )
 
 type Subjects struct {
-	schemaRegistryClient srclient.ISchemaRegistryClient
-	topic                string
+	client  *schema.Client
+	request schema.SubjectsRequest
 }
 
-func NewSubjects(
-	schemaRegistryClient srclient.ISchemaRegistryClient,
-	topic string,
-) (*Subjects, error) {
+func NewSubjects(client *schema.Client, request schema.SubjectsRequest) (*Subjects, error) {
 	return &Subjects{
-		schemaRegistryClient: schemaRegistryClient,
-		topic:                topic,
+		client:  client,
+		request: request,
 	}, nil
 }
 
 func (s *Subjects) Run(c context.Context) (interface{}, error) {
-	r, err := regexp.Compile(fmt.Sprintf(`%s-\w+-value`, s.topic))
+	response, err := s.client.Subjects(c, s.request)
 	if err != nil {
-		return nil, fmt.Errorf("can not use topic name: %w", err)
+		return nil, err
 	}
-
-	subjects, err := s.schemaRegistryClient.GetSubjects()
-	if err != nil {
-		return nil, fmt.Errorf("can not get subjects: %w", err)
+	if !s.request.IncludeDeleted {
+		return response.Subjects, nil
 	}
 
-	filtered := make([]string, 0, len(subjects))
-	for _, subject := range subjects {
-		if r.MatchString(subject) {
-			filtered = append(filtered, subject)
-		}
+	deleted := make(map[string]bool, len(response.Deleted))
+	for _, subject := range response.Deleted {
+		deleted[subject] = true
 	}
+	output := make([]subjectOutput, 0, len(response.Subjects))
+	for _, subject := range response.Subjects {
+		output = append(output, subjectOutput{
+			Subject: subject,
+			Deleted: deleted[subject],
+		})
+	}
+	return output, nil
+}
 
-	return filtered, nil
+type subjectOutput struct {
+	Subject string `json:"subject"`
+	Deleted bool   `json:"deleted"`
 }
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/youla-dev/schema/lib/schema"
+)
+
+type Undelete struct {
+	client  *schema.Client
+	request schema.UndeleteRequest
+}
+
+func NewUndelete(client *schema.Client, request schema.UndeleteRequest) (*Undelete, error) {
+	return &Undelete{
+		client:  client,
+		request: request,
+	}, nil
+}
+
+func (u *Undelete) Run(c context.Context) (interface{}, error) {
+	return u.client.Undelete(c, u.request)
+}
@@ -4,87 +4,97 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"path/filepath"
 
-	saramaCluster "github.com/bsm/sarama-cluster"
-	"github.com/riferrei/srclient"
-	"github.com/youla-dev/schema/lib/protoschema"
+	"github.com/youla-dev/schema/internal/report"
+	"github.com/youla-dev/schema/lib/schema"
 )
 
 type Validate struct {
-	schemaRegistryClient srclient.ISchemaRegistryClient
-	clusterClient        *saramaCluster.Client
-	topic, record        string
-	schemaBytes          []byte
+	client  *schema.Client
+	request schema.ValidateRequest
+	files   []ProtoFile
+	deleted []ProtoFile
+	lint    *Lint
+	report  string
 }
 
 func NewValidate(
-	schemaRegistryClient srclient.ISchemaRegistryClient,
-	clusterClient *saramaCluster.Client,
-	topic, record string,
-	schemaBytes []byte,
+	client *schema.Client,
+	request schema.ValidateRequest,
+	files []ProtoFile,
+	deleted []ProtoFile,
+	lint *Lint,
+	reportFormat string,
 ) (*Validate, error) {
+	if err := report.Validate(reportFormat); err != nil {
+		return nil, err
+	}
 	return &Validate{
-		schemaRegistryClient: schemaRegistryClient,
-		clusterClient:        clusterClient,
-		topic:                topic,
-		record:               record,
-		schemaBytes:          schemaBytes,
+		client:  client,
+		request: request,
+		files:   files,
+		deleted: deleted,
+		lint:    lint,
+		report:  reportFormat,
 	}, nil
 }
 
 func (v *Validate) Run(c context.Context) (interface{}, error) {
-	t := srclient.Protobuf
-	var err error
-	if v.topic == "" || v.record == "" {
-		v.topic, v.record, err = protoschema.Parse(context.Background(), v.schemaBytes)
-		if err != nil {
-			return nil, fmt.Errorf("can not extract topic and record from proto: %w", err)
+	// The findings of the lint are written instead of the validation result.
+	if v.lint != nil {
+		if output, err := v.lint.Run(c); err != nil {
+			return output, err
 		}
 	}
 
-	if v.topic == "" {
-		return nil, fmt.Errorf("topic %q is invalid", v.topic)
-	}
-
-	validatingSubject := subjectName(v.topic, v.record)
-
-	// Topic search
-	topics, err := v.clusterClient.Topics()
-	if err != nil {
-		return nil, fmt.Errorf("can not list topics: %w", err)
-	}
-	var topicExists bool
-	for _, topic := range topics {
-		if v.topic == topic {
-			topicExists = true
-			break
+	if len(v.files) == 1 && len(v.deleted) == 0 && v.report == "" {
+		response, err := v.client.Validate(c, v.fileRequest(v.files[0]))
+		if err != nil {
+			return nil, err
 		}
-	}
-	if !topicExists {
-		return fmt.Sprintf("topic %q not exist", v.topic), nil
-	}
-
-	subjects, err := v.schemaRegistryClient.GetSubjects()
-	if err != nil {
-		return nil, fmt.Errorf("can not get subjects: %w", err)
-	}
-	var subjectExist bool
-	for _, subject := range subjects {
-		subjectExist = subjectExist || subject == validatingSubject
+		if response.Status == schema.StatusSampleFailed || response.Status == schema.StatusPolicyDenied {
+			return nil, errors.New(response.String())
+		}
+		return response, nil
 	}
 
-	if !subjectExist {
-		return fmt.Sprintf("schema %q not exist yet", validatingSubject), nil
-	}
+	rep := &report.Report{Command: "validate"}
+	results := make([]fileResult, 0, len(v.files))
+	for _, file := range v.files {
+		request := v.fileRequest(file)
+		response, err := v.client.Validate(c, request)
+		if err != nil {
+			rep.Cases = append(rep.Cases, newCase(c, v.client.Options(), file, "", request.Record, report.Failed, err.Error()))
+			results = append(results, fileResult{File: file.Path, Error: err.Error()})
+			continue
+		}
 
-	// Is the scheme compatible
-	compatible, err := v.schemaRegistryClient.IsSchemaCompatible(validatingSubject, string(v.schemaBytes), "latest", t)
-	if err != nil {
-		return nil, fmt.Errorf("error validating schema: %w", err)
+		outcome := report.Passed
+		switch response.Status {
+		case schema.StatusTopicNotExist:
+			outcome = report.Skipped
+		case schema.StatusSampleFailed, schema.StatusPolicyDenied:
+			outcome = report.Failed
+		}
+		rep.Cases = append(rep.Cases, newCase(c, v.client.Options(), file, response.Subject, response.Record, outcome, response.String()))
+		results = append(results, fileResult{File: file.Path, Result: response})
 	}
-	if !compatible {
-		return nil, errors.New("schema is not compatible")
+	// The subjects of the deleted files are left in the registry.
+	for _, file := range v.deleted {
+		reportCase := newCase(c, v.client.Options(), file, "", "", report.Skipped, "proto file is deleted")
+		reportCase.Message = fmt.Sprintf("proto file is deleted, subject %q may be orphan", reportCase.Subject)
+		log.Println(reportCase.Message)
+		rep.Cases = append(rep.Cases, reportCase)
+		results = append(results, fileResult{File: file.Path, Orphan: reportCase.Subject})
 	}
+	return filesOutput(v.report, rep, results)
+}
 
-	return "schema is compatible", nil
+func (v *Validate) fileRequest(file ProtoFile) schema.ValidateRequest {
+	request := v.request
+	request.Schema = file.Schema
+	request.ImportDirs = append([]string{filepath.Dir(file.Path)}, request.ImportDirs...)
+	return request
 }
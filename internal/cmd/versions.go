@@ -2,47 +2,42 @@ package cmd
 
 import (
 	"context"
-	"errors"
-	"fmt"
 
-	"github.com/riferrei/srclient"
+	"github.com/youla-dev/schema/lib/schema"
 )
 
 type Versions struct {
-	schemaRegistryClient srclient.ISchemaRegistryClient
-	topic, record        string
+	client  *schema.Client
+	request schema.VersionsRequest
 }
 
-func NewVersions(
-	schemaRegistryClient srclient.ISchemaRegistryClient,
-	topic, record string,
-) (*Versions, error) {
+func NewVersions(client *schema.Client, request schema.VersionsRequest) (*Versions, error) {
 	return &Versions{
-		schemaRegistryClient: schemaRegistryClient,
-		topic:                topic,
-		record:               record,
+		client:  client,
+		request: request,
 	}, nil
 }
 
 func (v *Versions) Run(c context.Context) (interface{}, error) {
-	validatingSubject := subjectName(v.topic, v.record)
-
-	subjects, err := v.schemaRegistryClient.GetSubjects()
+	response, err := v.client.Versions(c, v.request)
 	if err != nil {
-		return nil, fmt.Errorf("can not get subjects: %w", err)
+		return nil, err
 	}
-	var subjectExist bool
-	for _, subject := range subjects {
-		subjectExist = subjectExist || subject == validatingSubject
+	if !v.request.IncludeDeleted {
+		return response.Versions, nil
 	}
 
-	if !subjectExist {
-		return nil, errors.New("schema not exist yet")
+	output := make([]versionOutput, 0, len(response.Versions))
+	for _, version := range response.Versions {
+		output = append(output, versionOutput{
+			Version: version,
+			Deleted: response.IsDeleted(version),
+		})
 	}
+	return output, nil
+}
 
-	versions, err := v.schemaRegistryClient.GetSchemaVersions(validatingSubject)
-	if err != nil {
-		return nil, fmt.Errorf("error getting versions: %w", err)
-	}
-	return versions, nil
+type versionOutput struct {
+	Version int  `json:"version"`
+	Deleted bool `json:"deleted"`
 }
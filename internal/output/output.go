@@ -0,0 +1,243 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of the command results.
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatTable    = "table"
+	FormatTemplate = "template"
+)
+
+// Writer writes the command results in the format.
+type Writer struct {
+	w        io.Writer
+	format   string
+	template *template.Template
+}
+
+// NewWriter creates the writer of the format. The template is required for FormatTemplate only.
+func NewWriter(w io.Writer, format, text string) (*Writer, error) {
+	writer := &Writer{
+		w:      w,
+		format: format,
+	}
+	switch format {
+	case FormatText, FormatJSON, FormatYAML, FormatTable:
+	case FormatTemplate:
+		if text == "" {
+			return nil, errors.New("template is not set")
+		}
+		t, err := template.New("output").Funcs(template.FuncMap{"json": toJSON}).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("can not parse template: %w", err)
+		}
+		writer.template = t
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+	return writer, nil
+}
+
+// Write writes the result. Nothing is written for the nil result.
+func (w *Writer) Write(result interface{}) error {
+	if result == nil || reflect.ValueOf(result).Kind() == reflect.Ptr && reflect.ValueOf(result).IsNil() {
+		return nil
+	}
+
+	switch w.format {
+	case FormatJSON:
+		return w.writeJSON(result)
+	case FormatYAML:
+		return w.writeYAML(result)
+	case FormatTable:
+		return w.writeTable(result)
+	case FormatTemplate:
+		if err := w.template.Execute(w.w, result); err != nil {
+			return fmt.Errorf("can not execute template: %w", err)
+		}
+		return nil
+	default:
+		return w.writeText(result)
+	}
+}
+
+// writeText writes the strings and the results describing themselves as is, the lists of scalars line by line
+// and other results in JSON.
+func (w *Writer) writeText(result interface{}) error {
+	switch t := result.(type) {
+	case string:
+		_, err := fmt.Fprintln(w.w, strings.TrimSuffix(t, "\n"))
+		return err
+	case fmt.Stringer:
+		_, err := fmt.Fprintln(w.w, strings.TrimSuffix(t.String(), "\n"))
+		return err
+	}
+
+	v := reflect.ValueOf(result)
+	if v.Kind() == reflect.Slice && isScalar(v.Type().Elem().Kind()) {
+		for i := 0; i < v.Len(); i++ {
+			if _, err := fmt.Fprintln(w.w, v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return w.writeJSON(result)
+}
+
+func (w *Writer) writeJSON(result interface{}) error {
+	output, err := json.MarshalIndent(result, "", "\t")
+	if err != nil {
+		return fmt.Errorf("can not marshall result: %w", err)
+	}
+	_, err = fmt.Fprintln(w.w, string(output))
+	return err
+}
+
+func (w *Writer) writeYAML(result interface{}) error {
+	node, err := toNode(result)
+	if err != nil {
+		return err
+	}
+	encoder := yaml.NewEncoder(w.w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return fmt.Errorf("can not marshall result: %w", err)
+	}
+	return encoder.Close()
+}
+
+// writeTable writes the list of objects as the table with the column per field. The single object is written
+// as the table of one row, the nested values are written in JSON.
+func (w *Writer) writeTable(result interface{}) error {
+	node, err := toNode(result)
+	if err != nil {
+		return err
+	}
+
+	var rows []*yaml.Node
+	switch node.Kind {
+	case yaml.SequenceNode:
+		rows = node.Content
+	case yaml.MappingNode:
+		rows = []*yaml.Node{node}
+	default:
+		_, err := fmt.Fprintln(w.w, node.Value)
+		return err
+	}
+
+	var columns []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		if row.Kind != yaml.MappingNode {
+			if !seen["value"] {
+				seen["value"] = true
+				columns = append(columns, "value")
+			}
+			continue
+		}
+		for i := 0; i < len(row.Content); i += 2 {
+			if key := row.Content[i].Value; !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(w.w, 0, 4, 2, ' ', 0)
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, strings.ToUpper(column))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		if row.Kind != yaml.MappingNode {
+			cells[0], err = cell(row)
+			if err != nil {
+				return err
+			}
+		} else {
+			for i := 0; i < len(row.Content); i += 2 {
+				for j, column := range columns {
+					if column == row.Content[i].Value {
+						if cells[j], err = cell(row.Content[i+1]); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// toNode converts the result to the YAML node through JSON, so the JSON field names and their order are kept.
+func toNode(result interface{}) (*yaml.Node, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("can not marshall result: %w", err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("can not convert result: %w", err)
+	}
+	node := document.Content[0]
+	resetStyle(node)
+	return node, nil
+}
+
+// resetStyle drops the flow style and the quotes of JSON.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+func cell(node *yaml.Node) (string, error) {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			return "", nil
+		}
+		return node.Value, nil
+	}
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return "", fmt.Errorf("can not convert result: %w", err)
+	}
+	return toJSON(value)
+}
+
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func isScalar(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+// Package report renders the results of validate and register for CI: JUnit XML, SARIF and GitHub annotations.
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Formats of the report.
+const (
+	FormatJUnit  = "junit"
+	FormatSARIF  = "sarif"
+	FormatGitHub = "github"
+)
+
+// Outcomes of the case.
+const (
+	Passed  = "passed"
+	Failed  = "failed"
+	Skipped = "skipped"
+)
+
+// Report holds the cases of the command, e.g. validate.
+type Report struct {
+	Command string
+	Cases   []Case
+}
+
+// Case is the result for the subject of the proto file. Line and column locate the record definition.
+type Case struct {
+	File    string
+	Line    int
+	Column  int
+	Subject string
+	Outcome string
+	Message string
+}
+
+// Failed counts the failed cases.
+func (r *Report) Failed() int {
+	var failed int
+	for _, c := range r.Cases {
+		if c.Outcome == Failed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Validate checks the format to be known.
+func Validate(format string) error {
+	switch format {
+	case "", FormatJUnit, FormatSARIF, FormatGitHub:
+		return nil
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// Render renders the report in the format.
+func Render(format string, r *Report) ([]byte, error) {
+	switch format {
+	case FormatJUnit:
+		return junit(r)
+	case FormatSARIF:
+		return sarif(r)
+	case FormatGitHub:
+		return github(r), nil
+	}
+	return nil, fmt.Errorf("unknown report format %q", format)
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func junit(r *Report) ([]byte, error) {
+	suite := junitSuite{
+		Name:  "schema " + r.Command,
+		Tests: len(r.Cases),
+	}
+	for _, c := range r.Cases {
+		testCase := junitCase{
+			Name:      c.Subject,
+			ClassName: c.File,
+			File:      c.File,
+			Line:      c.Line,
+		}
+		switch c.Outcome {
+		case Failed:
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: firstLine(c.Message), Text: fmt.Sprintf("%s:%d:%d: %s", c.File, c.Line, c.Column, c.Message)}
+		case Skipped:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: c.Message}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	output, err := xml.MarshalIndent(junitSuites{Suites: []junitSuite{suite}}, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("can not marshal junit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(output, '\n')...), nil
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// sarif reports the failed cases as errors and the skipped cases as notes.
+func sarif(r *Report) ([]byte, error) {
+	ruleID := "schema/" + r.Command
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "schema",
+			InformationURI: "https://github.com/youla-dev/schema",
+			Rules: []sarifRule{{
+				ID:               ruleID,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("Schema %s fails", r.Command)},
+			}},
+		}},
+		Results: []sarifResult{},
+	}
+	for _, c := range r.Cases {
+		var level string
+		switch c.Outcome {
+		case Failed:
+			level = "error"
+		case Skipped:
+			level = "note"
+		default:
+			continue
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  ruleID,
+			Level:   level,
+			Message: sarifMessage{Text: fmt.Sprintf("%s: %s", c.Subject, c.Message)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(c.File)},
+				Region:           sarifRegion{StartLine: c.Line, StartColumn: c.Column},
+			}}},
+		})
+	}
+
+	output, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("can not marshal sarif report: %w", err)
+	}
+	return append(output, '\n'), nil
+}
+
+// sarifURI converts the file path to the relative URI reference, escaping the spaces and the other characters
+// not allowed in URIs.
+func sarifURI(path string) string {
+	return (&url.URL{Path: filepath.ToSlash(path)}).String()
+}
+
+// github reports the failed cases as the error workflow commands and the skipped cases as the notices.
+func github(r *Report) []byte {
+	var b bytes.Buffer
+	for _, c := range r.Cases {
+		var command string
+		switch c.Outcome {
+		case Failed:
+			command = "error"
+		case Skipped:
+			command = "notice"
+		default:
+			continue
+		}
+		fmt.Fprintf(&b, "::%s file=%s,line=%d,col=%d,title=%s::%s\n", command,
+			githubProperty(c.File), c.Line, c.Column, githubProperty(c.Subject), githubData(c.Message))
+	}
+	return b.Bytes()
+}
+
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func githubProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(githubData(s))
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
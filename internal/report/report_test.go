@@ -0,0 +1,89 @@
+package report
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// testReport has the cases of every outcome with the characters escaped by the formats in the messages and paths.
+func testReport() *Report {
+	return &Report{
+		Command: "validate",
+		Cases: []Case{
+			{File: "proto/weather.proto", Line: 5, Column: 1, Subject: "weather-Weather-value", Outcome: Passed, Message: "schema is compatible"},
+			{
+				File:    "proto/a,b:c <d> & \"e\".proto",
+				Line:    12,
+				Column:  3,
+				Subject: "orders-Order:v1,beta-value",
+				Outcome: Failed,
+				Message: "deny: field <price> & \"total\" removed (100% sure)\nsecond line\r\nthird: a,b",
+			},
+			{File: "proto/currency.proto", Line: 1, Column: 1, Subject: "currency-Rate-value", Outcome: Skipped, Message: "topic \"currency\" not exist"},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format string
+		golden string
+	}{
+		{format: FormatJUnit, golden: "junit.xml"},
+		{format: FormatSARIF, golden: "sarif.json"},
+		{format: FormatGitHub, golden: "github.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := Render(tt.format, testReport())
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			golden := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Fatalf("Render() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	for _, format := range []string{FormatJUnit, FormatSARIF, FormatGitHub} {
+		if _, err := Render(format, &Report{Command: "register"}); err != nil {
+			t.Fatalf("Render(%s) of empty report error = %v", format, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, format := range []string{"", FormatJUnit, FormatSARIF, FormatGitHub} {
+		if err := Validate(format); err != nil {
+			t.Fatalf("Validate(%q) error = %v", format, err)
+		}
+	}
+	if err := Validate("html"); err == nil {
+		t.Fatal("Validate(html) error = nil")
+	}
+	if _, err := Render("html", testReport()); err == nil {
+		t.Fatal("Render(html) error = nil")
+	}
+}
+
+func TestFailed(t *testing.T) {
+	if failed := testReport().Failed(); failed != 1 {
+		t.Fatalf("Failed() = %d, want 1", failed)
+	}
+}
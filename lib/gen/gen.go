@@ -0,0 +1,189 @@
+// Package gen generates the Go code of the topic&record options of the messages, so the topic strings
+// are not duplicated by hand and the options are not read with the reflection.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	eproto "github.com/emicklei/proto"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+// Message is the top level message with the (topic) option.
+type Message struct {
+	// GoName is the name of the Go type generated by protoc-gen-go.
+	GoName  string
+	Topic   string
+	Record  string
+	Subject string
+}
+
+// File is the proto file with the annotated messages.
+type File struct {
+	Source    string
+	GoPackage string
+	Messages  []Message
+}
+
+// Parse reads the messages with the topic option and the Go package name of the go_package option from the proto file.
+// The options select the topic&record options, the file-level values are the defaults of the messages.
+// The messages without the topic option are skipped.
+func Parse(source string, protobuf []byte, options protoschema.Options) (*File, error) {
+	definition, err := eproto.NewParser(bytes.NewReader(protobuf)).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("can not parse %q: %w", source, err)
+	}
+	messages, err := options.Messages(protobuf)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse %q: %w", source, err)
+	}
+
+	file := &File{Source: source}
+	for _, element := range definition.Elements {
+		if e, ok := element.(*eproto.Option); ok && e.Name == "go_package" {
+			file.GoPackage = goPackageName(e.Constant.Source)
+		}
+	}
+	for _, m := range messages {
+		if m.Topic == "" {
+			continue
+		}
+		file.Messages = append(file.Messages, Message{
+			GoName:  goCamelCase(m.Message),
+			Topic:   m.Topic,
+			Record:  m.Record,
+			Subject: protoschema.SubjectName(m.Topic, m.Record),
+		})
+	}
+	return file, nil
+}
+
+var codeTemplate = template.Must(template.New("code").Parse(`// Code generated by schema gen. DO NOT EDIT.
+{{- range .Sources}}
+// source: {{.}}
+{{- end}}
+
+package {{.Package}}
+{{range .Messages}}
+// Values of the (topic) and (record) options of {{.GoName}}.
+const (
+	{{.GoName}}Topic   = {{printf "%q" .Topic}}
+	{{.GoName}}Record  = {{printf "%q" .Record}}
+	{{.GoName}}Subject = {{printf "%q" .Subject}}
+)
+
+// TopicRecord returns the values of the (topic) and (record) options of {{.GoName}}.
+func (*{{.GoName}}) TopicRecord() (string, string) {
+	return {{.GoName}}Topic, {{.GoName}}Record
+}
+{{end}}`))
+
+// Generate renders the Go code of the files. The files are to be generated into the same Go package,
+// goPackage overrides the package of the go_package options.
+func Generate(files []*File, goPackage string) ([]byte, error) {
+	data := struct {
+		Package  string
+		Sources  []string
+		Messages []Message
+	}{Package: goPackage}
+
+	names := map[string]string{}
+	sources := map[string]bool{}
+	for _, file := range files {
+		if goPackage == "" {
+			if file.GoPackage == "" {
+				return nil, fmt.Errorf("go package of %q is not set", file.Source)
+			}
+			if data.Package != "" && data.Package != file.GoPackage {
+				return nil, fmt.Errorf("files are in different go packages %q and %q", data.Package, file.GoPackage)
+			}
+			data.Package = file.GoPackage
+		}
+		// The messages of the descriptor set are parsed as the separate files of the same source.
+		if !sources[file.Source] {
+			sources[file.Source] = true
+			data.Sources = append(data.Sources, file.Source)
+		}
+		for _, message := range file.Messages {
+			if source, ok := names[message.GoName]; ok {
+				return nil, fmt.Errorf("message %q of %q is already declared in %q", message.GoName, file.Source, source)
+			}
+			names[message.GoName] = file.Source
+			data.Messages = append(data.Messages, message)
+		}
+	}
+	if !token.IsIdentifier(data.Package) {
+		return nil, fmt.Errorf("invalid go package name %q", data.Package)
+	}
+
+	var buf bytes.Buffer
+	if err := codeTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("can not generate code: %w", err)
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("can not format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// goPackageName returns the package name of the go_package option: the name after ";" or the last path element.
+func goPackageName(option string) string {
+	if i := strings.LastIndex(option, ";"); i >= 0 {
+		return option[i+1:]
+	}
+	name := path.Base(option)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if r, _ := utf8.DecodeRuneInString(name); unicode.IsDigit(r) {
+		name = "_" + name
+	}
+	return name
+}
+
+// goCamelCase converts the message name to the Go type name as protoc-gen-go does.
+func goCamelCase(name string) string {
+	var b []byte
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '.' && i+1 < len(name) && isASCIILower(name[i+1]):
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || name[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(name) && isASCIILower(name[i+1]):
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(name) && isASCIILower(name[i+1]); i++ {
+				b = append(b, name[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
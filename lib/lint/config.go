@@ -0,0 +1,125 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the name of the lint config file. The config of the nearest directory is applied to the proto file.
+const ConfigFile = ".schema-lint.yaml"
+
+// Naming conventions.
+const (
+	UpperCamelCase = "UpperCamelCase"
+	LowerCamelCase = "lowerCamelCase"
+	LowerSnakeCase = "lower_snake_case"
+	UpperSnakeCase = "UPPER_SNAKE_CASE"
+)
+
+// Elements requiring comments.
+const (
+	ElementMessage = "message"
+	ElementField   = "field"
+	ElementEnum    = "enum"
+)
+
+// Config holds the rules. The empty value disables the rule.
+type Config struct {
+	// RequireOptions requires the (topic) and (record) options of the files declaring the messages.
+	RequireOptions bool `yaml:"require_options"`
+	// RequirePackage requires the package declaration.
+	RequirePackage bool `yaml:"require_package"`
+	// Naming holds the naming conventions of the elements.
+	Naming Naming `yaml:"naming"`
+	// FieldNumbers limits the field numbers. The numbers reserved by Protocol Buffers are always forbidden.
+	FieldNumbers FieldNumbers `yaml:"field_numbers"`
+	// RequireReservedGaps requires the gaps of the field numbers to be reserved, e.g. for the removed fields.
+	RequireReservedGaps bool `yaml:"require_reserved_gaps"`
+	// RequireComments lists the elements requiring the leading comments: message, field or enum.
+	RequireComments []string `yaml:"require_comments"`
+	// ForbiddenTypes lists the forbidden field types, e.g. float or google.protobuf.Any.
+	ForbiddenTypes []string `yaml:"forbidden_types"`
+}
+
+// Naming holds the naming conventions: UpperCamelCase, lowerCamelCase, lower_snake_case or UPPER_SNAKE_CASE.
+type Naming struct {
+	Message   string `yaml:"message"`
+	Field     string `yaml:"field"`
+	Enum      string `yaml:"enum"`
+	EnumValue string `yaml:"enum_value"`
+}
+
+// FieldNumbers is the range of the field numbers.
+type FieldNumbers struct {
+	Min int `yaml:"min"`
+	Max int `yaml:"max"`
+}
+
+// DefaultConfig returns the rules applied without config.
+func DefaultConfig() Config {
+	return Config{
+		RequireOptions: true,
+		RequirePackage: true,
+		Naming: Naming{
+			Message:   UpperCamelCase,
+			Field:     LowerSnakeCase,
+			Enum:      UpperCamelCase,
+			EnumValue: UpperSnakeCase,
+		},
+		FieldNumbers: FieldNumbers{
+			Min: 1,
+			Max: 536870911,
+		},
+		RequireReservedGaps: true,
+	}
+}
+
+// LoadConfig reads the config file. The rules missing in the file are taken from DefaultConfig.
+func LoadConfig(path string) (Config, error) {
+	config := DefaultConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("can not read lint config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("can not parse lint config %q: %w", path, err)
+	}
+	return config, nil
+}
+
+// FindConfig finds the config file in the directory or in the nearest parent one. The empty path
+// is returned if there is none.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("can not find lint config: %w", err)
+	}
+	for {
+		path := filepath.Join(dir, ConfigFile)
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("can not find lint config: %w", err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// ConfigFor loads the config of the nearest directory of the proto file or DefaultConfig if there is none.
+func ConfigFor(protoFile string) (Config, error) {
+	path, err := FindConfig(filepath.Dir(protoFile))
+	if err != nil || path == "" {
+		return DefaultConfig(), err
+	}
+	return LoadConfig(path)
+}
@@ -0,0 +1,298 @@
+// Package lint checks the Protocol Buffers schemas against the house rules before the registration.
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/scanner"
+
+	eproto "github.com/emicklei/proto"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+// Rules reported by the findings.
+const (
+	RuleOptions      = "options"
+	RulePackage      = "package"
+	RuleNaming       = "naming"
+	RuleFieldNumbers = "field_numbers"
+	RuleReserved     = "reserved"
+	RuleComments     = "comments"
+	RuleTypes        = "forbidden_types"
+)
+
+// The field numbers reserved for the Protocol Buffers implementation.
+const (
+	implementationReservedMin = 19000
+	implementationReservedMax = 19999
+	maxFieldNumber            = 536870911
+)
+
+// maxGapSpans limits the spans listed by the reserved gaps finding.
+const maxGapSpans = 10
+
+var namingRegexps = map[string]*regexp.Regexp{
+	UpperCamelCase: regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	LowerCamelCase: regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	LowerSnakeCase: regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	UpperSnakeCase: regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`),
+}
+
+// Finding is the violation of the rule. Line and column start from 1.
+type Finding struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%d:%d: %s (%s)", f.Line, f.Column, f.Message, f.Rule)
+}
+
+// Lint checks the proto file against the rules of the config. The options select the topic&record options
+// required by the config. The findings are sorted by the position.
+func Lint(protobuf []byte, config Config, options protoschema.Options) ([]Finding, error) {
+	for _, naming := range []string{config.Naming.Message, config.Naming.Field, config.Naming.Enum, config.Naming.EnumValue} {
+		if _, ok := namingRegexps[naming]; naming != "" && !ok {
+			return nil, fmt.Errorf("unknown naming convention %q", naming)
+		}
+	}
+
+	definition, err := eproto.NewParser(bytes.NewBuffer(protobuf)).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("can not parse: %w", err)
+	}
+
+	messages, err := options.Messages(protobuf)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{config: config, options: options}
+	l.lintFile(definition, messages)
+	eproto.Walk(definition,
+		eproto.WithMessage(l.lintMessage),
+		eproto.WithEnum(l.lintEnum),
+		eproto.WithOneof(func(o *eproto.Oneof) {
+			for _, element := range o.Elements {
+				if f, ok := element.(*eproto.OneOfField); ok {
+					l.lintField(f.Field)
+				}
+			}
+		}),
+	)
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		if l.findings[i].Line != l.findings[j].Line {
+			return l.findings[i].Line < l.findings[j].Line
+		}
+		return l.findings[i].Column < l.findings[j].Column
+	})
+	return l.findings, nil
+}
+
+type linter struct {
+	config   Config
+	options  protoschema.Options
+	findings []Finding
+}
+
+func (l *linter) report(position scanner.Position, rule, format string, args ...interface{}) {
+	line, column := position.Line, position.Column
+	if line == 0 {
+		line, column = 1, 1
+	}
+	l.findings = append(l.findings, Finding{
+		Line:    line,
+		Column:  column,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) lintFile(definition *eproto.Proto, messages []protoschema.MessageOptions) {
+	var hasPackage, hasTopic, hasRecord bool
+	eproto.Walk(definition,
+		eproto.WithPackage(func(*eproto.Package) {
+			hasPackage = true
+		}),
+	)
+	for _, m := range messages {
+		hasTopic = hasTopic || m.Topic != ""
+		hasRecord = hasRecord || m.Record != ""
+	}
+
+	start := scanner.Position{Line: 1, Column: 1}
+	if l.config.RequirePackage && !hasPackage {
+		l.report(start, RulePackage, "package is not declared")
+	}
+	// The files without the messages, e.g. declaring the options, are not registered, so they need no options.
+	requireOptions := l.config.RequireOptions && len(messages) > 0
+	if requireOptions && !hasTopic {
+		l.report(start, RuleOptions, "(%s) option is not set", l.options.Topic)
+	}
+	if requireOptions && !hasRecord {
+		l.report(start, RuleOptions, "(%s) option is not set", l.options.Record)
+	}
+}
+
+func (l *linter) lintMessage(m *eproto.Message) {
+	if m.IsExtend {
+		return
+	}
+	l.lintName(m.Position, "message", m.Name, l.config.Naming.Message)
+	if l.requiresComment(ElementMessage) && m.Comment == nil {
+		l.report(m.Position, RuleComments, "message %s has no comment", m.Name)
+	}
+
+	numbers := map[int]bool{}
+	var reserved []eproto.Range
+	for _, element := range m.Elements {
+		switch e := element.(type) {
+		case *eproto.NormalField:
+			l.lintField(e.Field)
+			numbers[e.Sequence] = true
+		case *eproto.MapField:
+			l.lintField(e.Field)
+			l.lintType(e.Position, e.Name, e.KeyType)
+			numbers[e.Sequence] = true
+		case *eproto.Oneof:
+			for _, oneofElement := range e.Elements {
+				if f, ok := oneofElement.(*eproto.OneOfField); ok {
+					numbers[f.Sequence] = true
+				}
+			}
+		case *eproto.Reserved:
+			reserved = append(reserved, e.Ranges...)
+		}
+	}
+	if l.config.RequireReservedGaps {
+		l.lintGaps(m, numbers, reserved)
+	}
+}
+
+// lintGaps reports the spans of the field numbers missing between the used ones which are not reserved.
+// The spans are found from the sorted used and reserved numbers, so the sparse high numbers are cheap.
+func (l *linter) lintGaps(m *eproto.Message, numbers map[int]bool, reserved []eproto.Range) {
+	maxNumber := 0
+	taken := []numberSpan{{from: implementationReservedMin, to: implementationReservedMax}}
+	for number := range numbers {
+		if number > maxNumber {
+			maxNumber = number
+		}
+		taken = append(taken, numberSpan{from: number, to: number})
+	}
+	for _, r := range reserved {
+		to := r.To
+		if r.Max {
+			to = maxFieldNumber
+		}
+		if to < r.From {
+			to = r.From
+		}
+		taken = append(taken, numberSpan{from: r.From, to: to})
+	}
+	sort.Slice(taken, func(i, j int) bool { return taken[i].from < taken[j].from })
+
+	var gaps []numberSpan
+	next := 1
+	for _, span := range taken {
+		if next >= maxNumber {
+			break
+		}
+		if span.from > next {
+			to := span.from - 1
+			if to >= maxNumber {
+				to = maxNumber - 1
+			}
+			gaps = append(gaps, numberSpan{from: next, to: to})
+		}
+		if span.to+1 > next {
+			next = span.to + 1
+		}
+	}
+	if len(gaps) == 0 {
+		return
+	}
+
+	shown := gaps
+	if len(shown) > maxGapSpans {
+		shown = shown[:maxGapSpans]
+	}
+	spans := make([]string, 0, len(shown)+1)
+	for _, gap := range shown {
+		spans = append(spans, gap.String())
+	}
+	if len(gaps) > len(shown) {
+		spans = append(spans, fmt.Sprintf("and %d more spans", len(gaps)-len(shown)))
+	}
+	l.report(m.Position, RuleReserved, "message %s does not use nor reserve field numbers %s", m.Name, strings.Join(spans, ", "))
+}
+
+// numberSpan is the inclusive span of the field numbers.
+type numberSpan struct {
+	from, to int
+}
+
+func (s numberSpan) String() string {
+	if s.from == s.to {
+		return fmt.Sprint(s.from)
+	}
+	return fmt.Sprintf("%d to %d", s.from, s.to)
+}
+
+func (l *linter) lintField(f *eproto.Field) {
+	l.lintName(f.Position, "field", f.Name, l.config.Naming.Field)
+	if l.requiresComment(ElementField) && f.Comment == nil && f.InlineComment == nil {
+		l.report(f.Position, RuleComments, "field %s has no comment", f.Name)
+	}
+	l.lintType(f.Position, f.Name, f.Type)
+
+	numbers := l.config.FieldNumbers
+	switch {
+	case f.Sequence >= implementationReservedMin && f.Sequence <= implementationReservedMax:
+		l.report(f.Position, RuleFieldNumbers, "field %s number %d is reserved for the Protocol Buffers implementation", f.Name, f.Sequence)
+	case numbers.Min > 0 && f.Sequence < numbers.Min, numbers.Max > 0 && f.Sequence > numbers.Max:
+		l.report(f.Position, RuleFieldNumbers, "field %s number %d is out of range %d-%d", f.Name, f.Sequence, numbers.Min, numbers.Max)
+	}
+}
+
+func (l *linter) lintType(position scanner.Position, field, fieldType string) {
+	for _, forbidden := range l.config.ForbiddenTypes {
+		if strings.TrimPrefix(fieldType, ".") == strings.TrimPrefix(forbidden, ".") {
+			l.report(position, RuleTypes, "field %s has forbidden type %s", field, fieldType)
+		}
+	}
+}
+
+func (l *linter) lintEnum(e *eproto.Enum) {
+	l.lintName(e.Position, "enum", e.Name, l.config.Naming.Enum)
+	if l.requiresComment(ElementEnum) && e.Comment == nil {
+		l.report(e.Position, RuleComments, "enum %s has no comment", e.Name)
+	}
+	for _, element := range e.Elements {
+		if value, ok := element.(*eproto.EnumField); ok {
+			l.lintName(value.Position, "enum value", value.Name, l.config.Naming.EnumValue)
+		}
+	}
+}
+
+func (l *linter) lintName(position scanner.Position, kind, name, naming string) {
+	if naming == "" || namingRegexps[naming].MatchString(name) {
+		return
+	}
+	l.report(position, RuleNaming, "%s %s is not %s", kind, name, naming)
+}
+
+func (l *linter) requiresComment(element string) bool {
+	for _, e := range l.config.RequireComments {
+		if e == element {
+			return true
+		}
+	}
+	return false
+}
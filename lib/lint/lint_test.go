@@ -0,0 +1,198 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+const validProto = `syntax = "proto3";
+
+package weather;
+
+// Weather is the current weather.
+message Weather {
+  option (topic) = "weather";
+  option (record) = "Weather";
+
+  // Condition of the sky.
+  enum Condition {
+    CONDITION_UNSPECIFIED = 0;
+    CLEAR_SKY = 1;
+  }
+
+  string city = 1;
+  double celsius = 2;
+  Condition condition = 3;
+}
+`
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		protobuf string
+		config   func(*Config)
+		want     []Finding
+	}{
+		{
+			name:     "valid",
+			protobuf: validProto,
+		},
+		{
+			name:     "options",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nmessage Weather {\n  option (topic) = \"weather\";\n}\n",
+			want:     []Finding{{Line: 1, Column: 1, Rule: RuleOptions, Message: "(record) option is not set"}},
+		},
+		{
+			name:     "options file without messages",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nimport \"google/protobuf/descriptor.proto\";\nextend google.protobuf.MessageOptions {\n  string topic = 50001;\n}\n",
+		},
+		{
+			name:     "package",
+			protobuf: "syntax = \"proto3\";\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n}\n",
+			want:     []Finding{{Line: 1, Column: 1, Rule: RulePackage, Message: "package is not declared"}},
+		},
+		{
+			name:     "naming",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nmessage weather_v2 {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  string cityName = 1;\n  enum kind {\n    clear = 0;\n  }\n}\n",
+			want: []Finding{
+				{Line: 3, Column: 1, Rule: RuleNaming, Message: "message weather_v2 is not UpperCamelCase"},
+				{Line: 6, Column: 3, Rule: RuleNaming, Message: "field cityName is not lower_snake_case"},
+				{Line: 7, Column: 3, Rule: RuleNaming, Message: "enum kind is not UpperCamelCase"},
+				{Line: 8, Column: 5, Rule: RuleNaming, Message: "enum value clear is not UPPER_SNAKE_CASE"},
+			},
+		},
+		{
+			name:     "field numbers",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  reserved 2 to 18999, 20000 to 99;\n  string city = 1;\n  string name = 19000;\n}\n",
+			config:   func(c *Config) { c.FieldNumbers.Max = 100; c.RequireReservedGaps = false },
+			want: []Finding{
+				{Line: 8, Column: 3, Rule: RuleFieldNumbers, Message: "field name number 19000 is reserved for the Protocol Buffers implementation"},
+			},
+		},
+		{
+			name:     "field numbers range",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  string city = 1;\n  string name = 101;\n}\n",
+			config:   func(c *Config) { c.FieldNumbers.Max = 100; c.RequireReservedGaps = false },
+			want: []Finding{
+				{Line: 7, Column: 3, Rule: RuleFieldNumbers, Message: "field name number 101 is out of range 1-100"},
+			},
+		},
+		{
+			name:     "reserved gaps",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  reserved 2;\n  string city = 1;\n  string name = 5;\n}\n",
+			want: []Finding{
+				{Line: 3, Column: 1, Rule: RuleReserved, Message: "message Weather does not use nor reserve field numbers 3 to 4"},
+			},
+		},
+		{
+			name:     "reserved gaps of sparse numbers",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  reserved 3, 5 to 9;\n  string city = 1;\n  string name = 536870911;\n}\n",
+			want: []Finding{
+				{Line: 3, Column: 1, Rule: RuleReserved, Message: "message Weather does not use nor reserve field numbers 2, 4, 10 to 18999, 20000 to 536870910"},
+			},
+		},
+		{
+			name:     "reserved gaps to max",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  reserved 2 to max;\n  string city = 1;\n  string name = 536870911;\n}\n",
+		},
+		{
+			name:     "reserved gaps summary",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  string f1 = 1;\n  string f3 = 3;\n  string f5 = 5;\n  string f7 = 7;\n  string f9 = 9;\n  string f11 = 11;\n  string f13 = 13;\n  string f15 = 15;\n  string f17 = 17;\n  string f19 = 19;\n  string f21 = 21;\n  string f23 = 23;\n  string f25 = 25;\n}\n",
+			want: []Finding{
+				{Line: 3, Column: 1, Rule: RuleReserved, Message: "message Weather does not use nor reserve field numbers 2, 4, 6, 8, 10, 12, 14, 16, 18, 20, and 2 more spans"},
+			},
+		},
+		{
+			name:     "comments",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  string city = 1; // the city name\n  string name = 2;\n}\n",
+			config:   func(c *Config) { c.RequireComments = []string{ElementMessage, ElementField} },
+			want: []Finding{
+				{Line: 3, Column: 1, Rule: RuleComments, Message: "message Weather has no comment"},
+				{Line: 7, Column: 3, Rule: RuleComments, Message: "field name has no comment"},
+			},
+		},
+		{
+			name:     "forbidden types",
+			protobuf: "syntax = \"proto3\";\npackage weather;\nimport \"google/protobuf/any.proto\";\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  float celsius = 1;\n  google.protobuf.Any details = 2;\n  map<string, float> readings = 3;\n}\n",
+			config:   func(c *Config) { c.ForbiddenTypes = []string{"float", ".google.protobuf.Any"} },
+			want: []Finding{
+				{Line: 7, Column: 3, Rule: RuleTypes, Message: "field celsius has forbidden type float"},
+				{Line: 8, Column: 3, Rule: RuleTypes, Message: "field details has forbidden type google.protobuf.Any"},
+				{Line: 9, Column: 3, Rule: RuleTypes, Message: "field readings has forbidden type float"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			if tt.config != nil {
+				tt.config(&config)
+			}
+			got, err := Lint([]byte(tt.protobuf), config, protoschema.DefaultOptions)
+			if err != nil {
+				t.Fatalf("Lint() error = %v", err)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Lint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintErrors(t *testing.T) {
+	config := DefaultConfig()
+	config.Naming.Field = "kebab-case"
+	if _, err := Lint([]byte(validProto), config, protoschema.DefaultOptions); err == nil {
+		t.Error("Lint() with unknown naming error = nil")
+	}
+	if _, err := Lint([]byte("message {"), DefaultConfig(), protoschema.DefaultOptions); err == nil {
+		t.Error("Lint() of invalid proto error = nil")
+	}
+}
+
+func TestExamples(t *testing.T) {
+	files, err := filepath.Glob("../../example/*.proto")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("example proto files not found: %v", err)
+	}
+	for _, file := range files {
+		protobuf, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		findings, err := Lint(protobuf, DefaultConfig(), protoschema.DefaultOptions)
+		if err != nil || len(findings) > 0 {
+			t.Errorf("Lint(%s) = %v, %v, want no findings", file, findings, err)
+		}
+	}
+}
+
+func TestConfigFor(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "events", "v1")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config := "require_package: false\nnaming:\n  field: lowerCamelCase\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ConfigFor(filepath.Join(nested, "weather.proto"))
+	if err != nil {
+		t.Fatalf("ConfigFor() error = %v", err)
+	}
+	want := DefaultConfig()
+	want.RequirePackage = false
+	want.Naming.Field = LowerCamelCase
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ConfigFor() = %+v, want %+v", got, want)
+	}
+}
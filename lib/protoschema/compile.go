@@ -0,0 +1,111 @@
+package protoschema
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	eproto "github.com/emicklei/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+)
+
+// Compile builds the descriptor of the named file. The files map holds the proto sources of the file
+// and its imports by the import paths. The imports missing in the map are looked up in the import
+// directories. The well-known google/protobuf imports are built in.
+func Compile(name string, files map[string]string, importDirs ...string) (*desc.FileDescriptor, error) {
+	return compile(name, files, false, importDirs)
+}
+
+// CompileWithSourceInfo builds the descriptor as Compile does keeping the source code info:
+// the locations and the comments of the declarations.
+func CompileWithSourceInfo(name string, files map[string]string, importDirs ...string) (*desc.FileDescriptor, error) {
+	return compile(name, files, true, importDirs)
+}
+
+func compile(name string, files map[string]string, sourceInfo bool, importDirs []string) (*desc.FileDescriptor, error) {
+	fromMap := protoparse.FileContentsFromMap(files)
+	parser := protoparse.Parser{
+		IncludeSourceCodeInfo: sourceInfo,
+		Accessor: func(filename string) (io.ReadCloser, error) {
+			r, err := fromMap(filename)
+			if err == nil || !errors.Is(err, os.ErrNotExist) {
+				return r, err
+			}
+			for _, dir := range importDirs {
+				f, err := os.Open(filepath.Join(dir, filename))
+				if err == nil {
+					return f, nil
+				}
+			}
+			return nil, err
+		},
+	}
+	fds, err := parser.ParseFiles(name)
+	if err != nil {
+		return nil, fmt.Errorf("can not compile %q: %w", name, err)
+	}
+	return fds[0], nil
+}
+
+// RecordMessage returns the name of the top level message with the (record) option value.
+// The only top level message of the schema is returned regardless of its options.
+func RecordMessage(ctx context.Context, protobuf []byte, record string) (string, error) {
+	return DefaultOptions.RecordMessage(ctx, protobuf, record)
+}
+
+// Imports returns the import paths of the proto file in the order of declaration.
+func Imports(protobuf []byte) ([]string, error) {
+	parser := eproto.NewParser(bytes.NewBuffer(protobuf))
+	definition, err := parser.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("can not parse: %w", err)
+	}
+
+	var imports []string
+	for _, element := range definition.Elements {
+		if i, ok := element.(*eproto.Import); ok {
+			imports = append(imports, i.Filename)
+		}
+	}
+	return imports, nil
+}
+
+// Names returns the names of the messages and the fields of the proto file including the nested ones.
+func Names(protobuf []byte) ([]string, error) {
+	parser := eproto.NewParser(bytes.NewBuffer(protobuf))
+	definition, err := parser.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("can not parse: %w", err)
+	}
+
+	var names []string
+	eproto.Walk(definition,
+		eproto.WithMessage(func(m *eproto.Message) {
+			if m.IsExtend {
+				return
+			}
+			names = append(names, m.Name)
+			for _, element := range m.Elements {
+				switch f := element.(type) {
+				case *eproto.NormalField:
+					names = append(names, f.Name)
+				case *eproto.MapField:
+					names = append(names, f.Name)
+				}
+			}
+		}),
+		eproto.WithOneof(func(o *eproto.Oneof) {
+			for _, element := range o.Elements {
+				if f, ok := element.(*eproto.OneOfField); ok {
+					names = append(names, f.Name)
+				}
+			}
+		}),
+	)
+	return names, nil
+}
@@ -0,0 +1,257 @@
+package protoschema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoprint"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	// messageOptions is the extendee of the (topic) and (record) options.
+	messageOptions = "google.protobuf.MessageOptions"
+	// fileOptions is the extendee of the file-level defaults of the (topic) and (record) options.
+	fileOptions = "google.protobuf.FileOptions"
+)
+
+// DescriptorSchema is the schema of the message rendered from the descriptor set.
+type DescriptorSchema struct {
+	Message string
+	Topic   string
+	Record  string
+	Schema  []byte
+}
+
+// IsDescriptorSet checks the file to be the compiled FileDescriptorSet or the Buf image by its extension.
+func IsDescriptorSet(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pb", ".binpb":
+		return true
+	}
+	return false
+}
+
+// FromDescriptorSet renders every top level message with the (topic) or (record) option of the serialized
+// FileDescriptorSet or Buf image to the canonical proto file. The file holds the message and the types it depends on
+// from the same package, the types of the other packages and the custom options are imported.
+// The imports missing in the set, e.g. the well-known google/protobuf types, are resolved with the built-in ones.
+func FromDescriptorSet(data []byte) ([]DescriptorSchema, error) {
+	return DefaultOptions.FromDescriptorSet(data)
+}
+
+// FromDescriptorSet renders the messages with the topic or record option as the package FromDescriptorSet does.
+// The options set in the file are the defaults of its messages.
+func (o Options) FromDescriptorSet(data []byte) ([]DescriptorSchema, error) {
+	// Buf image is the FileDescriptorSet with the extra fields ignored here.
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("can not decode descriptor set: %w", err)
+	}
+	if err := addBuiltinImports(&set); err != nil {
+		return nil, err
+	}
+	files, err := desc.CreateFileDescriptorsFromSet(&set)
+	if err != nil {
+		return nil, fmt.Errorf("can not build descriptor set: %w", err)
+	}
+
+	ordered := make([]*desc.FileDescriptor, 0, len(set.GetFile()))
+	for _, fdp := range set.GetFile() {
+		ordered = append(ordered, files[fdp.GetName()])
+	}
+
+	var schemas []DescriptorSchema
+	for _, fd := range ordered {
+		for _, md := range fd.GetMessageTypes() {
+			topic, err := messageOption(md, o.Topic, o.fileTopic())
+			if err != nil {
+				return nil, err
+			}
+			record, err := messageOption(md, o.Record, o.fileRecord())
+			if err != nil {
+				return nil, err
+			}
+			if topic == "" && record == "" {
+				continue
+			}
+			schema, err := renderMessage(md, ordered)
+			if err != nil {
+				return nil, err
+			}
+			schemas = append(schemas, DescriptorSchema{
+				Message: md.GetFullyQualifiedName(),
+				Topic:   topic,
+				Record:  record,
+				Schema:  schema,
+			})
+		}
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no message with (%s) or (%s) option in descriptor set", o.Topic, o.Record)
+	}
+	return schemas, nil
+}
+
+// addBuiltinImports adds the imports missing in the set which are linked into the utility.
+func addBuiltinImports(set *descriptorpb.FileDescriptorSet) error {
+	present := map[string]bool{}
+	for _, fdp := range set.GetFile() {
+		present[fdp.GetName()] = true
+	}
+	var builtin []*descriptorpb.FileDescriptorProto
+	var add func(names []string) error
+	add = func(names []string) error {
+		for _, name := range names {
+			if present[name] {
+				continue
+			}
+			fd, err := desc.LoadFileDescriptor(name)
+			if err != nil {
+				return fmt.Errorf("import %q is missing in descriptor set", name)
+			}
+			present[name] = true
+			if err := add(fd.AsFileDescriptorProto().GetDependency()); err != nil {
+				return err
+			}
+			builtin = append(builtin, fd.AsFileDescriptorProto())
+		}
+		return nil
+	}
+	for _, fdp := range set.GetFile() {
+		if err := add(fdp.GetDependency()); err != nil {
+			return err
+		}
+	}
+	set.File = append(builtin, set.File...)
+	return nil
+}
+
+// messageOption reads the option of the message of the descriptor set or the file-level default.
+// The missing option is the empty value.
+func messageOption(md *desc.MessageDescriptor, option, fileOption Option) (string, error) {
+	value, err := stringOptionOrDefault(md.UnwrapMessage(), option, fileOption)
+	if errors.Is(err, ErrOptionNotSet) || errors.Is(err, ErrOptionNotDeclared) {
+		return "", nil
+	}
+	return value, err
+}
+
+// renderMessage prints the proto file of the message with its dependencies. The files of the set keep the order
+// of the declarations.
+func renderMessage(md *desc.MessageDescriptor, files []*desc.FileDescriptor) ([]byte, error) {
+	fd := md.GetFile()
+	out := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(fd.GetName()),
+		Package: proto.String(fd.GetPackage()),
+		Options: fd.GetFileOptions(),
+	}
+	if fd.AsFileDescriptorProto().Syntax != nil {
+		out.Syntax = proto.String(fd.AsFileDescriptorProto().GetSyntax())
+	}
+
+	// The types are inlined by their top level declarations, the types of the other packages are imported.
+	inlined := map[desc.Descriptor]bool{}
+	imports := map[string]*desc.FileDescriptor{}
+	var include func(d desc.Descriptor)
+	include = func(d desc.Descriptor) {
+		top := topLevel(d)
+		if inlined[top] {
+			return
+		}
+		dfd := top.GetFile()
+		if dfd.GetPackage() != fd.GetPackage() || strings.HasPrefix(dfd.GetName(), "google/protobuf/") {
+			imports[dfd.GetName()] = dfd
+			return
+		}
+		inlined[top] = true
+		if m, ok := top.(*desc.MessageDescriptor); ok {
+			walkFields(m, func(field *desc.FieldDescriptor) {
+				if field.GetMessageType() != nil {
+					include(field.GetMessageType())
+				}
+				if field.GetEnumType() != nil {
+					include(field.GetEnumType())
+				}
+			})
+		}
+	}
+	include(md)
+
+	// The files declaring the extensions, e.g. the (topic) and (record) options, are imported for the custom options.
+	for _, dep := range fd.GetDependencies() {
+		if len(dep.GetExtensions()) > 0 {
+			imports[dep.GetName()] = dep
+		}
+	}
+	for _, ext := range fd.GetExtensions() {
+		if owner := ext.GetOwner().GetFullyQualifiedName(); owner == messageOptions || owner == fileOptions {
+			out.Extension = append(out.Extension, ext.AsFieldDescriptorProto())
+			extendee := ext.GetOwner().GetFile()
+			imports[extendee.GetName()] = extendee
+		}
+	}
+
+	for _, file := range files {
+		for _, m := range file.GetMessageTypes() {
+			if inlined[m] {
+				out.MessageType = append(out.MessageType, m.AsDescriptorProto())
+			}
+		}
+		for _, e := range file.GetEnumTypes() {
+			if inlined[e] {
+				out.EnumType = append(out.EnumType, e.AsEnumDescriptorProto())
+			}
+		}
+	}
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		if name != fd.GetName() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	deps := make([]*desc.FileDescriptor, 0, len(names))
+	for _, name := range names {
+		out.Dependency = append(out.Dependency, name)
+		deps = append(deps, imports[name])
+	}
+
+	rendered, err := desc.CreateFileDescriptor(out, deps...)
+	if err != nil {
+		return nil, fmt.Errorf("can not extract %q: %w", md.GetFullyQualifiedName(), err)
+	}
+	var buf bytes.Buffer
+	if err := (&protoprint.Printer{}).PrintProtoFile(rendered, &buf); err != nil {
+		return nil, fmt.Errorf("can not render %q: %w", md.GetFullyQualifiedName(), err)
+	}
+	return buf.Bytes(), nil
+}
+
+// topLevel returns the top level declaration of the nested type.
+func topLevel(d desc.Descriptor) desc.Descriptor {
+	for {
+		parent := d.GetParent()
+		if _, ok := parent.(*desc.FileDescriptor); ok || parent == nil {
+			return d
+		}
+		d = parent
+	}
+}
+
+// walkFields calls fn for the fields of the message and its nested messages.
+func walkFields(md *desc.MessageDescriptor, fn func(*desc.FieldDescriptor)) {
+	for _, field := range md.GetFields() {
+		fn(field)
+	}
+	for _, nested := range md.GetNestedMessageTypes() {
+		walkFields(nested, fn)
+	}
+}
@@ -0,0 +1,274 @@
+package protoschema
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	eproto "github.com/emicklei/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Options selects the topic&record options. The message options override the file-level ones, so the file
+// sets the defaults of its messages, e.g. the topic of all records. The file-level options are the extensions
+// of google.protobuf.FileOptions selected with FileTopic and FileRecord, or with Topic and Record if not set.
+type Options struct {
+	Topic      Option
+	Record     Option
+	FileTopic  Option
+	FileRecord Option
+}
+
+// DefaultOptions are the (topic) and (record) options of any package.
+var DefaultOptions = Options{Topic: TopicOption, Record: RecordOption}
+
+func (o Options) fileTopic() Option {
+	if o.FileTopic.isZero() {
+		return o.Topic
+	}
+	return o.FileTopic
+}
+
+func (o Options) fileRecord() Option {
+	if o.FileRecord.isZero() {
+		return o.Record
+	}
+	return o.FileRecord
+}
+
+// TopicRecord reads the topic&record options of the message descriptor as the package TopicRecord does,
+// the file-level options are the defaults of the message.
+func (o Options) TopicRecord(md protoreflect.MessageDescriptor) (string, string, error) {
+	topic, err := stringOptionOrDefault(md, o.Topic, o.fileTopic())
+	if err != nil {
+		return "", "", err
+	}
+	if o.Record.isZero() {
+		return topic, "", nil
+	}
+	record, err := stringOptionOrDefault(md, o.Record, o.fileRecord())
+	if err != nil {
+		return "", "", err
+	}
+	return topic, record, nil
+}
+
+// MessageOptions are the topic&record values of the top level message with the file-level defaults applied.
+type MessageOptions struct {
+	Message string
+	Topic   string
+	Record  string
+}
+
+// Matches checks the option name of the proto source, e.g. "(kafka.topic)", to refer the option. The short option
+// name matches the extension of any package. The full name matches the fully qualified form, e.g. "(.kafka.topic)",
+// and the forms relative to the package of the file or its parents, e.g. "(topic)" or "(v1.topic)"
+// within the events.v1 package for the events.v1.topic option.
+func (o Option) Matches(source, pkg string) bool {
+	name := o.name()
+	if name == "" || !strings.HasPrefix(source, "(") || !strings.HasSuffix(source, ")") {
+		return false
+	}
+	ref := strings.TrimSuffix(strings.TrimPrefix(source, "("), ")")
+
+	if !strings.Contains(name, ".") {
+		return ref == name || strings.HasSuffix(ref, "."+name)
+	}
+	if strings.HasPrefix(ref, ".") {
+		return ref[1:] == name
+	}
+	for scope := pkg; ; {
+		if scope == "" {
+			return ref == name
+		}
+		if scope+"."+ref == name {
+			return true
+		}
+		i := strings.LastIndex(scope, ".")
+		if i < 0 {
+			scope = ""
+		} else {
+			scope = scope[:i]
+		}
+	}
+}
+
+// optionsFile holds the topic&record options of the proto file and of its top level messages.
+type optionsFile struct {
+	topic, record *eproto.Option
+	messages      []optionsMessage
+}
+
+// optionsMessage holds the topic&record options of the message and of its nested messages.
+type optionsMessage struct {
+	message       *eproto.Message
+	topic, record *eproto.Option
+	nested        []optionsMessage
+}
+
+// defaults returns the file-level values.
+func (f *optionsFile) defaults() MessageOptions {
+	values := MessageOptions{}
+	if f.topic != nil {
+		values.Topic = f.topic.Constant.Source
+	}
+	if f.record != nil {
+		values.Record = f.record.Constant.Source
+	}
+	return values
+}
+
+func (f *optionsFile) values(m optionsMessage) MessageOptions {
+	return m.values(f.defaults())
+}
+
+// values overrides the defaults, e.g. of the file or of the enclosing message, with the options of the message.
+func (m optionsMessage) values(defaults MessageOptions) MessageOptions {
+	values := defaults
+	values.Message = m.message.Name
+	if m.topic != nil {
+		values.Topic = m.topic.Constant.Source
+	}
+	if m.record != nil {
+		values.Record = m.record.Constant.Source
+	}
+	return values
+}
+
+func (o Options) parseFile(protobuf []byte) (*optionsFile, error) {
+	definition, err := eproto.NewParser(bytes.NewBuffer(protobuf)).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("can not parse: %w", err)
+	}
+
+	var pkg string
+	for _, element := range definition.Elements {
+		if p, ok := element.(*eproto.Package); ok {
+			pkg = p.Name
+		}
+	}
+
+	file := &optionsFile{}
+	for _, element := range definition.Elements {
+		switch e := element.(type) {
+		case *eproto.Option:
+			Options{Topic: o.fileTopic(), Record: o.fileRecord()}.match(e, pkg, &file.topic, &file.record)
+		case *eproto.Message:
+			if !e.IsExtend {
+				file.messages = append(file.messages, o.parseMessage(e, pkg))
+			}
+		}
+	}
+	return file, nil
+}
+
+func (o Options) parseMessage(message *eproto.Message, pkg string) optionsMessage {
+	m := optionsMessage{message: message}
+	for _, element := range message.Elements {
+		switch e := element.(type) {
+		case *eproto.Option:
+			o.match(e, pkg, &m.topic, &m.record)
+		case *eproto.Message:
+			if !e.IsExtend {
+				m.nested = append(m.nested, o.parseMessage(e, pkg))
+			}
+		}
+	}
+	return m
+}
+
+func (o Options) match(option *eproto.Option, pkg string, topic, record **eproto.Option) {
+	if o.Topic.Matches(option.Name, pkg) {
+		*topic = option
+	}
+	if o.Record.Matches(option.Name, pkg) {
+		*record = option
+	}
+}
+
+// Messages reads the topic&record values of the top level messages of the proto file.
+func (o Options) Messages(protobuf []byte) ([]MessageOptions, error) {
+	file, err := o.parseFile(protobuf)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]MessageOptions, 0, len(file.messages))
+	for _, m := range file.messages {
+		messages = append(messages, file.values(m))
+	}
+	return messages, nil
+}
+
+// Parse loads topic&record option values from the original proto file. The values of the last annotated message,
+// top level or nested, are returned, the file-level values are returned for the file without the annotated messages.
+// The nested message inherits the values of the enclosing messages and of the file.
+func (o Options) Parse(ctx context.Context, protobuf []byte) (string, string, error) {
+	file, err := o.parseFile(protobuf)
+	if err != nil {
+		return "", "", err
+	}
+
+	values := file.defaults()
+	var visit func(m optionsMessage, defaults MessageOptions)
+	visit = func(m optionsMessage, defaults MessageOptions) {
+		messageValues := m.values(defaults)
+		if m.topic != nil || m.record != nil {
+			values = messageValues
+		}
+		for _, nested := range m.nested {
+			visit(nested, messageValues)
+		}
+	}
+	for _, m := range file.messages {
+		visit(m, file.defaults())
+	}
+	return values.Topic, values.Record, nil
+}
+
+// RecordMessage returns the name of the top level message with the record option value.
+// The only top level message of the schema is returned regardless of its options.
+func (o Options) RecordMessage(ctx context.Context, protobuf []byte, record string) (string, error) {
+	file, err := o.parseFile(protobuf)
+	if err != nil {
+		return "", err
+	}
+	if len(file.messages) == 1 {
+		return file.messages[0].message.Name, nil
+	}
+	for _, m := range file.messages {
+		if m.record != nil && m.record.Constant.Source == record {
+			return m.message.Name, nil
+		}
+	}
+	return "", fmt.Errorf("message with record %q not found", record)
+}
+
+// Locate returns the position of the record definition in the proto file: the record option with the value,
+// the only top level message or the topic option. The start of the file is returned
+// if none is found. The position of the syntax error is returned if the file can not be parsed.
+func (o Options) Locate(protobuf []byte, record string) Position {
+	file, err := o.parseFile(protobuf)
+	if err != nil {
+		return errorPosition(err)
+	}
+
+	topic := file.topic
+	for _, m := range file.messages {
+		if m.record != nil && m.record.Constant.Source == record {
+			return position(m.record.Position)
+		}
+		if topic == nil {
+			topic = m.topic
+		}
+	}
+	switch {
+	case file.record != nil && file.record.Constant.Source == record:
+		return position(file.record.Position)
+	case len(file.messages) == 1:
+		return position(file.messages[0].message.Position)
+	case topic != nil:
+		return position(topic.Position)
+	}
+	return Position{Line: 1, Column: 1}
+}
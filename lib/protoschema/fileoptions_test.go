@@ -0,0 +1,78 @@
+package protoschema
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOptionsParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		protobuf string
+		topic    string
+		record   string
+	}{
+		{
+			name:     "top level message",
+			protobuf: "syntax = \"proto3\";\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n}\n",
+			topic:    "weather",
+			record:   "Weather",
+		},
+		{
+			name:     "nested message",
+			protobuf: "syntax = \"proto3\";\nmessage Envelope {\n  message Weather {\n    option (topic) = \"weather\";\n    option (record) = \"Weather\";\n  }\n}\n",
+			topic:    "weather",
+			record:   "Weather",
+		},
+		{
+			name:     "nested message inherits enclosing message",
+			protobuf: "syntax = \"proto3\";\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n  message Reading {\n    option (record) = \"Reading\";\n  }\n}\n",
+			topic:    "weather",
+			record:   "Reading",
+		},
+		{
+			name:     "nested message inherits file",
+			protobuf: "syntax = \"proto3\";\noption (topic) = \"weather\";\nmessage Envelope {\n  message Weather {\n    option (record) = \"Weather\";\n  }\n}\n",
+			topic:    "weather",
+			record:   "Weather",
+		},
+		{
+			name:     "last annotated message",
+			protobuf: "syntax = \"proto3\";\nmessage Weather {\n  option (topic) = \"weather\";\n  option (record) = \"Weather\";\n}\nmessage Plain {\n  message Nested {}\n}\nmessage Currency {\n  option (topic) = \"currency\";\n}\n",
+			topic:    "currency",
+		},
+		{
+			name:     "file options",
+			protobuf: "syntax = \"proto3\";\noption (topic) = \"weather\";\noption (record) = \"Weather\";\nmessage Weather {}\n",
+			topic:    "weather",
+			record:   "Weather",
+		},
+		{
+			name:     "named options",
+			options:  Options{Topic: NewOption("kafka.topic", 0), Record: NewOption("kafka.record", 0)},
+			protobuf: "syntax = \"proto3\";\npackage kafka;\nmessage Envelope {\n  option (other.topic) = \"other\";\n  message Weather {\n    option (kafka.topic) = \"weather\";\n    option (.kafka.record) = \"Weather\";\n  }\n}\n",
+			topic:    "weather",
+			record:   "Weather",
+		},
+		{
+			name:     "no options",
+			protobuf: "syntax = \"proto3\";\nmessage Weather {\n  message Reading {}\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			if options.Topic.isZero() {
+				options = DefaultOptions
+			}
+			topic, record, err := options.Parse(context.Background(), []byte(tt.protobuf))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if topic != tt.topic || record != tt.record {
+				t.Fatalf("Parse() = %q, %q, want %q, %q", topic, record, tt.topic, tt.record)
+			}
+		})
+	}
+}
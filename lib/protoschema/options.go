@@ -0,0 +1,290 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+var (
+	// ErrOptionNotSet is returned when the message has no value of the option.
+	ErrOptionNotSet = errors.New("option is not set")
+	// ErrOptionType is returned when the option is not a string.
+	ErrOptionType = errors.New("option is not a string")
+	// ErrOptionNotDeclared is returned when the extension of the option is found neither in the registry
+	// nor in the file of the message and its imports.
+	ErrOptionNotDeclared = errors.New("option is not declared")
+)
+
+// OptionError describes the option of the message which can not be read.
+type OptionError struct {
+	Message protoreflect.FullName
+	Option  string
+	Err     error
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("option (%s) of message %q: %v", e.Option, e.Message, e.Err)
+}
+
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// Option selects the message option by its extension type. If the type is not linked in, the option is discovered
+// by the name of the extension of google.protobuf.MessageOptions: the short name, e.g. "topic", matches the extension
+// of any package, the full name, e.g. "example.topic", matches the one of the package. The number, if set, reads
+// the message option which declaration is not found, e.g. in the Buf image built without the imports.
+type Option struct {
+	Type   protoreflect.ExtensionType
+	Name   string
+	Number protoreflect.FieldNumber
+}
+
+var (
+	// TopicOption discovers the (topic) option by its name.
+	TopicOption = Option{Name: "topic"}
+	// RecordOption discovers the (record) option by its name.
+	RecordOption = Option{Name: "record"}
+)
+
+// NewOption selects the option by its name as written in the proto file, e.g. "(kafka.topic)", "kafka.topic"
+// or ".kafka.topic", and the optional field number.
+func NewOption(name string, number protoreflect.FieldNumber) Option {
+	name = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(name), "("), ")")
+	return Option{Name: strings.TrimPrefix(name, "."), Number: number}
+}
+
+func (o Option) isZero() bool {
+	return o.Type == nil && o.Name == "" && o.Number == 0
+}
+
+func (o Option) String() string {
+	if o.Type == nil && o.Name == "" && o.Number != 0 {
+		return strconv.Itoa(int(o.Number))
+	}
+	return o.name()
+}
+
+func (o Option) name() string {
+	if o.Type != nil {
+		return string(o.Type.TypeDescriptor().FullName())
+	}
+	return o.Name
+}
+
+func (o Option) matches(xd protoreflect.ExtensionTypeDescriptor, extendee protoreflect.FullName) bool {
+	if o.Type != nil {
+		return xd.FullName() == o.Type.TypeDescriptor().FullName()
+	}
+	if o.Name == "" {
+		return extendee == messageOptions && xd.ContainingMessage().FullName() == extendee && xd.Number() == o.Number
+	}
+	return matchesName(xd, extendee, o.Name)
+}
+
+func matchesName(xd protoreflect.FieldDescriptor, extendee protoreflect.FullName, name string) bool {
+	if xd.ContainingMessage().FullName() != extendee {
+		return false
+	}
+	if strings.Contains(name, ".") {
+		return string(xd.FullName()) == name
+	}
+	return string(xd.Name()) == name
+}
+
+// MessageTopicRecord reads the topic&record options of the message. See TopicRecord.
+func MessageTopicRecord(m proto.Message, topic, record Option) (string, string, error) {
+	return TopicRecord(m.ProtoReflect().Descriptor(), topic, record)
+}
+
+// TopicRecord reads the topic&record options of the message descriptor. The record is not read if its option
+// is the zero value. The errors are *OptionError wrapping ErrOptionNotSet, ErrOptionType or ErrOptionNotDeclared.
+func TopicRecord(md protoreflect.MessageDescriptor, topic, record Option) (string, string, error) {
+	topicValue, err := StringOption(md, topic)
+	if err != nil {
+		return "", "", err
+	}
+	if record.isZero() {
+		return topicValue, "", nil
+	}
+	recordValue, err := StringOption(md, record)
+	if err != nil {
+		return "", "", err
+	}
+	return topicValue, recordValue, nil
+}
+
+// StringOption reads the string option of the message descriptor. The option not set on the message is read from
+// the options of its file, so the file sets the default of its messages. The option set with the extension which
+// is not linked in is read from the unknown fields of the options by the extension declared in the file
+// of the message or in its imports.
+func StringOption(md protoreflect.MessageDescriptor, option Option) (string, error) {
+	return stringOptionOrDefault(md, option, option)
+}
+
+// stringOptionOrDefault reads the option of the message, then the file option if the message option is not set.
+func stringOptionOrDefault(md protoreflect.MessageDescriptor, option, fileOption Option) (string, error) {
+	value, err := stringOption(md.Options(), messageOptions, md.ParentFile(), option)
+	if errors.Is(err, ErrOptionNotSet) || errors.Is(err, ErrOptionNotDeclared) {
+		fileValue, fileErr := stringOption(md.ParentFile().Options(), fileOptions, md.ParentFile(), fileOption)
+		switch {
+		case errors.Is(fileErr, ErrOptionNotDeclared):
+		case errors.Is(fileErr, ErrOptionNotSet):
+			err = fileErr
+		default:
+			value, err = fileValue, fileErr
+		}
+	}
+	if err != nil {
+		return "", &OptionError{Message: md.FullName(), Option: option.String(), Err: err}
+	}
+	return value, nil
+}
+
+func stringOption(
+	o protoreflect.ProtoMessage,
+	extendee protoreflect.FullName,
+	fd protoreflect.FileDescriptor,
+	option Option,
+) (string, error) {
+	var options protoreflect.Message
+	if o != nil && o.ProtoReflect().IsValid() {
+		options = o.ProtoReflect()
+	}
+
+	var (
+		value string
+		found bool
+		err   error
+	)
+	if options != nil {
+		options.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			xd, ok := fd.(protoreflect.ExtensionTypeDescriptor)
+			if !ok || !option.matches(xd, extendee) {
+				return true
+			}
+			found = true
+			if fd.Kind() != protoreflect.StringKind || fd.IsList() {
+				err = ErrOptionType
+				return false
+			}
+			value = v.String()
+			return false
+		})
+	}
+	if found || err != nil {
+		return value, err
+	}
+
+	// The number of the message option is not the one of the file option, the file option is to be declared.
+	var number protoreflect.FieldNumber
+	if extendee == messageOptions {
+		number = option.Number
+	}
+	if xd := findExtension(fd, extendee, option); xd != nil {
+		if xd.Kind() != protoreflect.StringKind || xd.IsList() {
+			return "", ErrOptionType
+		}
+		number = xd.Number()
+	}
+	if number == 0 {
+		return "", ErrOptionNotDeclared
+	}
+	if options == nil {
+		return "", ErrOptionNotSet
+	}
+	return unknownString(options.GetUnknown(), number)
+}
+
+// findExtension looks up the declaration of the option in the file and in its imports, then in the registry.
+func findExtension(
+	fd protoreflect.FileDescriptor,
+	extendee protoreflect.FullName,
+	option Option,
+) protoreflect.FieldDescriptor {
+	if option.Type != nil {
+		if option.Type.TypeDescriptor().ContainingMessage().FullName() != extendee {
+			return nil
+		}
+		return option.Type.TypeDescriptor()
+	}
+	if option.Name == "" {
+		return nil
+	}
+
+	visited := map[string]bool{}
+	var walk func(fd protoreflect.FileDescriptor) protoreflect.FieldDescriptor
+	walk = func(fd protoreflect.FileDescriptor) protoreflect.FieldDescriptor {
+		if visited[fd.Path()] {
+			return nil
+		}
+		visited[fd.Path()] = true
+		extensions := fd.Extensions()
+		for i := 0; i < extensions.Len(); i++ {
+			if matchesName(extensions.Get(i), extendee, option.Name) {
+				return extensions.Get(i)
+			}
+		}
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			if xd := walk(imports.Get(i).FileDescriptor); xd != nil {
+				return xd
+			}
+		}
+		return nil
+	}
+	if xd := walk(fd); xd != nil {
+		return xd
+	}
+
+	var found protoreflect.FieldDescriptor
+	protoregistry.GlobalTypes.RangeExtensionsByMessage(extendee, func(xt protoreflect.ExtensionType) bool {
+		if matchesName(xt.TypeDescriptor(), extendee, option.Name) {
+			found = xt.TypeDescriptor()
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// unknownString reads the last value of the string field from the unknown fields.
+func unknownString(unknown protoreflect.RawFields, number protoreflect.FieldNumber) (string, error) {
+	var (
+		value string
+		found bool
+	)
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+		if num == number {
+			if typ != protowire.BytesType {
+				return "", ErrOptionType
+			}
+			v, n := protowire.ConsumeBytes(unknown)
+			if n < 0 {
+				return "", protowire.ParseError(n)
+			}
+			value, found = string(v), true
+		}
+		n = protowire.ConsumeFieldValue(num, typ, unknown)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+	}
+	if !found {
+		return "", ErrOptionNotSet
+	}
+	return value, nil
+}
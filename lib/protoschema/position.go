@@ -0,0 +1,37 @@
+package protoschema
+
+import (
+	"regexp"
+	"strconv"
+	"text/scanner"
+)
+
+var errorPositionRegexp = regexp.MustCompile(`:(\d+):(\d+): `)
+
+// Position is the position in the proto file. Line and column start from 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Locate returns the position of the record definition in the proto file: the (record) option with the value,
+// the only top level message or the (topic) option. The start of the file is returned
+// if none is found. The position of the syntax error is returned if the file can not be parsed.
+func Locate(protobuf []byte, record string) Position {
+	return DefaultOptions.Locate(protobuf, record)
+}
+
+// errorPosition extracts the position from the parse error, e.g. "<input>:3:1: found ...".
+func errorPosition(err error) Position {
+	match := errorPositionRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return Position{Line: 1, Column: 1}
+	}
+	line, _ := strconv.Atoi(match[1])
+	column, _ := strconv.Atoi(match[2])
+	return Position{Line: line, Column: column}
+}
+
+func position(p scanner.Position) Position {
+	return Position{Line: p.Line, Column: p.Column}
+}
@@ -4,56 +4,55 @@
 package protoschema
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 
-	eproto "github.com/emicklei/proto"
-	"github.com/golang/protobuf/descriptor"
+	protoV1 "github.com/golang/protobuf/proto"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/runtime/protoiface"
 )
 
-// Parse loads topic&record option values from the original proto file.
-func Parse(ctx context.Context, protobuf []byte) (string, string, error) {
-	parser := eproto.NewParser(bytes.NewBuffer(protobuf))
-	definition, err := parser.Parse()
-
-	if err != nil {
-		return "", "", fmt.Errorf("can not parse: %w", err)
-	}
+// ErrNotMessage is returned for the value which is neither the generated message nor the legacy one.
+var ErrNotMessage = errors.New("value is not a protobuf message")
 
-	var topic, record string
-
-	eproto.Walk(definition,
-		eproto.WithOption(func(option *eproto.Option) {
-			if option.Name == "(topic)" {
-				topic = option.Constant.Source
-			}
-			if option.Name == "(record)" {
-				record = option.Constant.Source
-			}
-		}),
-	)
-
-	return topic, record, nil
+// Parse loads topic&record option values from the original proto file. See Options.Parse.
+func Parse(ctx context.Context, protobuf []byte) (string, string, error) {
+	return DefaultOptions.Parse(ctx, protobuf)
 }
 
 // ExtractTopicRecord loads topic&record option values from the generated golang code.
 // If the recordOption is not specified, the second output string would be empty.
+// The options which are not set are the empty values. The message is the proto.Message or the legacy
+// github.com/golang/protobuf message, ErrNotMessage is returned for the other values.
+//
+// Deprecated: use MessageTopicRecord or TopicRecord reporting the missing options.
 func ExtractTopicRecord(m interface{}, topicOption, recordOption protoreflect.ExtensionType) (string, string, error) {
-	_, md := descriptor.MessageDescriptorProto(m)
-	var topic, record string
-	topicRaw := proto.GetExtension(md.GetOptions(), topicOption)
-	if topicRaw != nil {
-		topic = topicRaw.(string)
+	md, err := messageDescriptor(m)
+	if err != nil {
+		return "", "", err
 	}
+	topic, err := StringOption(md, Option{Type: topicOption})
+	if err != nil && !errors.Is(err, ErrOptionNotSet) {
+		return "", "", err
+	}
+	var record string
 	if recordOption != nil {
-		recordRaw := proto.GetExtension(md.GetOptions(), recordOption)
-		if recordRaw != nil {
-			record = recordRaw.(string)
+		record, err = StringOption(md, Option{Type: recordOption})
+		if err != nil && !errors.Is(err, ErrOptionNotSet) {
+			return "", "", err
 		}
 	}
-
 	return topic, record, nil
 }
+
+func messageDescriptor(m interface{}) (protoreflect.MessageDescriptor, error) {
+	switch m := m.(type) {
+	case proto.Message:
+		return m.ProtoReflect().Descriptor(), nil
+	case protoiface.MessageV1:
+		return protoV1.MessageV2(m).ProtoReflect().Descriptor(), nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrNotMessage, m)
+}
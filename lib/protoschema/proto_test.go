@@ -0,0 +1,45 @@
+package protoschema
+
+import (
+	"errors"
+	"testing"
+
+	protoV1 "github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestExtractTopicRecord(t *testing.T) {
+	fd, topicOption, recordOption := compileSerde(t)
+	message := func(name string) *dynamicpb.Message {
+		return dynamicpb.NewMessage(fd.FindMessage(name).UnwrapMessage())
+	}
+
+	tests := []struct {
+		name         string
+		message      interface{}
+		recordOption protoreflect.ExtensionType
+		topic        string
+		record       string
+		wantErr      error
+	}{
+		{name: "message", message: message("serde.Weather"), recordOption: recordOption, topic: "weather", record: "Weather"},
+		{name: "nested message", message: message("serde.Weather.Reading"), recordOption: recordOption, topic: "readings", record: "Reading"},
+		{name: "without record option", message: message("serde.Weather"), topic: "weather"},
+		{name: "options not set", message: message("serde.Plain"), recordOption: recordOption},
+		{name: "legacy message", message: protoV1.MessageV1(message("serde.Weather")), recordOption: recordOption, topic: "weather", record: "Weather"},
+		{name: "struct", message: struct{}{}, recordOption: recordOption, wantErr: ErrNotMessage},
+		{name: "nil", message: nil, recordOption: recordOption, wantErr: ErrNotMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic, record, err := ExtractTopicRecord(tt.message, topicOption, tt.recordOption)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExtractTopicRecord() error = %v, want %v", err, tt.wantErr)
+			}
+			if topic != tt.topic || record != tt.record {
+				t.Fatalf("ExtractTopicRecord() = %q, %q, want %q, %q", topic, record, tt.topic, tt.record)
+			}
+		})
+	}
+}
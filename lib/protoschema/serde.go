@@ -0,0 +1,259 @@
+package protoschema
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	eproto "github.com/emicklei/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoprint"
+	"github.com/riferrei/srclient"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SubjectName returns the subject for the value of the topic&record according to TopicRecordNameStrategy.
+func SubjectName(topic, record string) string {
+	return topic + "-" + record + "-value"
+}
+
+// Serializer encodes the messages to the Confluent wire format. The subject of the message is defined
+// by the topic&record options, the schema ID is looked up in the registry once per message type.
+type Serializer struct {
+	schemaRegistryClient      srclient.ISchemaRegistryClient
+	topicOption, recordOption protoreflect.ExtensionType
+	autoRegister              bool
+
+	mu         sync.Mutex
+	records    map[protoreflect.FullName]serializerRecord
+	references map[string]srclient.Reference
+}
+
+type serializerRecord struct {
+	topic    string
+	schemaID int
+	indexes  []int
+}
+
+// NewSerializer creates the serializer. If autoRegister is set, the missing schemas are registered
+// together with their imports, otherwise the schema must be registered beforehand.
+func NewSerializer(
+	schemaRegistryClient srclient.ISchemaRegistryClient,
+	topicOption, recordOption protoreflect.ExtensionType,
+	autoRegister bool,
+) *Serializer {
+	return &Serializer{
+		schemaRegistryClient: schemaRegistryClient,
+		topicOption:          topicOption,
+		recordOption:         recordOption,
+		autoRegister:         autoRegister,
+		records:              make(map[protoreflect.FullName]serializerRecord),
+		references:           make(map[string]srclient.Reference),
+	}
+}
+
+// Serialize returns the topic of the message and its value in the Confluent wire format.
+func (s *Serializer) Serialize(m proto.Message) (string, []byte, error) {
+	record, err := s.record(m.ProtoReflect().Descriptor())
+	if err != nil {
+		return "", nil, err
+	}
+
+	payload, err := proto.Marshal(m)
+	if err != nil {
+		return "", nil, fmt.Errorf("can not marshal message: %w", err)
+	}
+
+	return record.topic, append(AppendHeader(nil, record.schemaID, record.indexes), payload...), nil
+}
+
+func (s *Serializer) record(md protoreflect.MessageDescriptor) (serializerRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[md.FullName()]; ok {
+		return record, nil
+	}
+
+	recordOption := Option{}
+	if s.recordOption != nil {
+		recordOption = Option{Type: s.recordOption}
+	}
+	topic, recordName, err := TopicRecord(md, Option{Type: s.topicOption}, recordOption)
+	if err != nil {
+		return serializerRecord{}, err
+	}
+	if topic == "" {
+		return serializerRecord{}, fmt.Errorf("message %q has no topic option", md.FullName())
+	}
+
+	schema, err := s.schema(SubjectName(topic, recordName), md.ParentFile())
+	if err != nil {
+		return serializerRecord{}, err
+	}
+
+	record := serializerRecord{
+		topic:    topic,
+		schemaID: schema.ID(),
+		indexes:  MessageIndexes(md),
+	}
+	s.records[md.FullName()] = record
+	return record, nil
+}
+
+// schema looks up or registers the file under the subject. The imports of the file are registered
+// as the references with the subjects named after the import paths.
+func (s *Serializer) schema(subject string, fd protoreflect.FileDescriptor) (*srclient.Schema, error) {
+	references := make([]srclient.Reference, 0, fd.Imports().Len())
+	for i := 0; i < fd.Imports().Len(); i++ {
+		imported := fd.Imports().Get(i).FileDescriptor
+		if isWellKnown(imported.Path()) {
+			continue
+		}
+		reference, ok := s.references[imported.Path()]
+		if !ok {
+			schema, err := s.schema(imported.Path(), imported)
+			if err != nil {
+				return nil, err
+			}
+			reference = srclient.Reference{
+				Name:    imported.Path(),
+				Subject: imported.Path(),
+				Version: schema.Version(),
+			}
+			s.references[imported.Path()] = reference
+		}
+		references = append(references, reference)
+	}
+
+	text, err := PrintFile(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema *srclient.Schema
+	if s.autoRegister {
+		schema, err = s.schemaRegistryClient.CreateSchema(subject, text, srclient.Protobuf, references...)
+	} else {
+		schema, err = s.schemaRegistryClient.LookupSchema(subject, text, srclient.Protobuf, references...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not get schema for subject %q: %w", subject, err)
+	}
+	return schema, nil
+}
+
+// PrintFile renders the file descriptor to the proto source.
+func PrintFile(fd protoreflect.FileDescriptor) (string, error) {
+	file, err := desc.WrapFile(fd)
+	if err != nil {
+		return "", fmt.Errorf("can not wrap file %q: %w", fd.Path(), err)
+	}
+	text, err := (&protoprint.Printer{}).PrintProtoToString(file)
+	if err != nil {
+		return "", fmt.Errorf("can not print file %q: %w", fd.Path(), err)
+	}
+	return text, nil
+}
+
+func isWellKnown(path string) bool {
+	return strings.HasPrefix(path, "google/protobuf/")
+}
+
+// Deserializer decodes the messages from the Confluent wire format. The schema of every payload is
+// loaded from the registry once per schema ID to check the payload to be written with the message type.
+type Deserializer struct {
+	schemaRegistryClient srclient.ISchemaRegistryClient
+
+	mu      sync.Mutex
+	schemas map[int]*eproto.Proto
+}
+
+// NewDeserializer creates the deserializer.
+func NewDeserializer(schemaRegistryClient srclient.ISchemaRegistryClient) *Deserializer {
+	return &Deserializer{
+		schemaRegistryClient: schemaRegistryClient,
+		schemas:              make(map[int]*eproto.Proto),
+	}
+}
+
+// Deserialize decodes the payload in the Confluent wire format to the message.
+func (d *Deserializer) Deserialize(payload []byte, m proto.Message) error {
+	schemaID, indexes, body, err := DecodeHeader(payload)
+	if err != nil {
+		return err
+	}
+
+	name, err := d.messageName(schemaID, indexes)
+	if err != nil {
+		return err
+	}
+	md := m.ProtoReflect().Descriptor()
+	if name != string(md.FullName()) {
+		return fmt.Errorf("payload of schema %d contains message %q, not %q", schemaID, name, md.FullName())
+	}
+
+	if err := proto.Unmarshal(body, m); err != nil {
+		return fmt.Errorf("can not unmarshal message: %w", err)
+	}
+	return nil
+}
+
+// messageName returns the full name of the message located by the indexes within the schema.
+func (d *Deserializer) messageName(schemaID int, indexes []int) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	definition, ok := d.schemas[schemaID]
+	if !ok {
+		schema, err := d.schemaRegistryClient.GetSchema(schemaID)
+		if err != nil {
+			return "", fmt.Errorf("can not get schema %d: %w", schemaID, err)
+		}
+		definition, err = eproto.NewParser(bytes.NewBufferString(schema.Schema())).Parse()
+		if err != nil {
+			return "", fmt.Errorf("can not parse schema %d: %w", schemaID, err)
+		}
+		d.schemas[schemaID] = definition
+	}
+
+	names, err := MessageNames(definition, indexes)
+	if err != nil {
+		return "", fmt.Errorf("schema %d: %w", schemaID, err)
+	}
+	var pkg string
+	eproto.Walk(definition, eproto.WithPackage(func(p *eproto.Package) {
+		pkg = p.Name
+	}))
+	if pkg != "" {
+		names = append([]string{pkg}, names...)
+	}
+	return strings.Join(names, "."), nil
+}
+
+// MessageNames walks the nested messages of the parsed schema along the message indexes.
+func MessageNames(definition *eproto.Proto, indexes []int) ([]string, error) {
+	elements := definition.Elements
+	names := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		var message *eproto.Message
+		var i int
+		for _, element := range elements {
+			if m, ok := element.(*eproto.Message); ok && !m.IsExtend {
+				if i == index {
+					message = m
+					break
+				}
+				i++
+			}
+		}
+		if message == nil {
+			return nil, fmt.Errorf("message index %v not found", indexes)
+		}
+		names = append(names, message.Name)
+		elements = message.Elements
+	}
+	return names, nil
+}
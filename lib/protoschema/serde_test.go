@@ -0,0 +1,179 @@
+package protoschema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/riferrei/srclient"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const serdeProto = `syntax = "proto3";
+
+package serde;
+
+import "google/protobuf/descriptor.proto";
+
+extend google.protobuf.MessageOptions {
+  string topic = 50001;
+  string record = 50002;
+}
+
+message Plain {
+  string value = 1;
+}
+
+message Weather {
+  option (topic) = "weather";
+  option (record) = "Weather";
+
+  message Reading {
+    option (topic) = "readings";
+    option (record) = "Reading";
+
+    double celsius = 1;
+  }
+
+  string city = 1;
+}
+`
+
+func compileSerde(t *testing.T) (*desc.FileDescriptor, protoreflect.ExtensionType, protoreflect.ExtensionType) {
+	t.Helper()
+	fd, err := Compile("serde.proto", map[string]string{"serde.proto": serdeProto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	extensionType := func(name string) protoreflect.ExtensionType {
+		xd := fd.FindExtensionByName(name).UnwrapField().(protoreflect.ExtensionDescriptor)
+		return dynamicpb.NewExtensionType(xd)
+	}
+	return fd, extensionType("serde.topic"), extensionType("serde.record")
+}
+
+func TestSerializerDeserializer(t *testing.T) {
+	fd, topicOption, recordOption := compileSerde(t)
+	client := srclient.CreateMockSchemaRegistryClient("http://registry")
+	serializer := NewSerializer(client, topicOption, recordOption, true)
+	deserializer := NewDeserializer(client)
+
+	tests := []struct {
+		message   string
+		field     string
+		value     protoreflect.Value
+		topic     string
+		subject   string
+		indexes   []int
+		wrongType string
+	}{
+		{
+			message:   "serde.Weather",
+			field:     "city",
+			value:     protoreflect.ValueOfString("Moscow"),
+			topic:     "weather",
+			subject:   "weather-Weather-value",
+			indexes:   []int{1},
+			wrongType: "serde.Weather.Reading",
+		},
+		{
+			message:   "serde.Weather.Reading",
+			field:     "celsius",
+			value:     protoreflect.ValueOfFloat64(-12.5),
+			topic:     "readings",
+			subject:   "readings-Reading-value",
+			indexes:   []int{1, 0},
+			wrongType: "serde.Weather",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			md := fd.FindMessage(tt.message).UnwrapMessage()
+			m := dynamicpb.NewMessage(md)
+			m.Set(md.Fields().ByName(protoreflect.Name(tt.field)), tt.value)
+
+			topic, payload, err := serializer.Serialize(m)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if topic != tt.topic {
+				t.Fatalf("Serialize() topic = %q, want %q", topic, tt.topic)
+			}
+			schema, err := client.GetLatestSchema(tt.subject)
+			if err != nil {
+				t.Fatalf("subject %q is not registered: %v", tt.subject, err)
+			}
+			id, indexes, _, err := DecodeHeader(payload)
+			if err != nil {
+				t.Fatalf("DecodeHeader() error = %v", err)
+			}
+			if id != schema.ID() || !equalInts(indexes, tt.indexes) {
+				t.Fatalf("header = %d, %v, want %d, %v", id, indexes, schema.ID(), tt.indexes)
+			}
+
+			decoded := dynamicpb.NewMessage(md)
+			if err := deserializer.Deserialize(payload, decoded); err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+			if !proto.Equal(m, decoded) {
+				t.Fatalf("Deserialize() = %v, want %v", decoded, m)
+			}
+
+			wrong := dynamicpb.NewMessage(fd.FindMessage(tt.wrongType).UnwrapMessage())
+			if err := deserializer.Deserialize(payload, wrong); err == nil || !strings.Contains(err.Error(), "contains message") {
+				t.Fatalf("Deserialize() to %s error = %v, want message mismatch", tt.wrongType, err)
+			}
+		})
+	}
+}
+
+func TestSerializerWithoutTopic(t *testing.T) {
+	fd, topicOption, recordOption := compileSerde(t)
+	serializer := NewSerializer(srclient.CreateMockSchemaRegistryClient("http://registry"), topicOption, recordOption, true)
+
+	_, _, err := serializer.Serialize(dynamicpb.NewMessage(fd.FindMessage("serde.Plain").UnwrapMessage()))
+	if err == nil {
+		t.Fatal("Serialize() error = nil, want missing topic error")
+	}
+}
+
+func TestDeserializerPackage(t *testing.T) {
+	fd, _, _ := compileSerde(t)
+	client := srclient.CreateMockSchemaRegistryClient("http://registry")
+	deserializer := NewDeserializer(client)
+	weather := fd.FindMessage("serde.Weather").UnwrapMessage()
+
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{name: "same package", schema: "syntax = \"proto3\";\npackage serde;\nmessage Weather {\n  string city = 1;\n}\n"},
+		{name: "other package", schema: "syntax = \"proto3\";\npackage other;\nmessage Weather {\n  string city = 1;\n}\n", wantErr: true},
+		{name: "no package", schema: "syntax = \"proto3\";\nmessage Weather {\n  string city = 1;\n}\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := client.CreateSchema(strings.ReplaceAll(tt.name, " ", "-")+"-value", tt.schema, srclient.Protobuf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := dynamicpb.NewMessage(weather)
+			m.Set(weather.Fields().ByName("city"), protoreflect.ValueOfString("Moscow"))
+			body, err := proto.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = deserializer.Deserialize(append(AppendHeader(nil, schema.ID(), []int{0}), body...), dynamicpb.NewMessage(weather))
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "contains message")) {
+				t.Fatalf("Deserialize() error = %v, want message mismatch", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package protoschema
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// magicByte starts every payload in the Confluent wire format.
+const magicByte = 0
+
+// ErrInvalidPayload is returned for the payload which is not in the Confluent wire format.
+var ErrInvalidPayload = errors.New("payload is not in the confluent wire format")
+
+// AppendHeader appends the Confluent wire format header to the buffer: the magic byte, the schema ID
+// and the message indexes of the record within the schema.
+func AppendHeader(b []byte, schemaID int, indexes []int) []byte {
+	var id [4]byte
+	binary.BigEndian.PutUint32(id[:], uint32(schemaID))
+	b = append(append(b, magicByte), id[:]...)
+
+	// The first message of the schema is encoded with the single zero byte.
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(b, 0)
+	}
+	b = appendVarint(b, len(indexes))
+	for _, index := range indexes {
+		b = appendVarint(b, index)
+	}
+	return b
+}
+
+func appendVarint(b []byte, v int) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], int64(v))
+	return append(b, buf[:n]...)
+}
+
+// DecodeHeader splits the payload in the Confluent wire format to the schema ID, the message indexes
+// and the protobuf message bytes.
+func DecodeHeader(payload []byte) (int, []int, []byte, error) {
+	if len(payload) < 6 || payload[0] != magicByte {
+		return 0, nil, nil, ErrInvalidPayload
+	}
+	schemaID := int(binary.BigEndian.Uint32(payload[1:5]))
+	rest := payload[5:]
+
+	count, n := binary.Varint(rest)
+	if n <= 0 || count < 0 || count > int64(len(rest)) {
+		return 0, nil, nil, fmt.Errorf("%w: invalid message indexes", ErrInvalidPayload)
+	}
+	rest = rest[n:]
+	if count == 0 {
+		return schemaID, []int{0}, rest, nil
+	}
+
+	indexes := make([]int, 0, count)
+	for i := int64(0); i < count; i++ {
+		index, n := binary.Varint(rest)
+		if n <= 0 || index < 0 {
+			return 0, nil, nil, fmt.Errorf("%w: invalid message indexes", ErrInvalidPayload)
+		}
+		indexes = append(indexes, int(index))
+		rest = rest[n:]
+	}
+	return schemaID, indexes, rest, nil
+}
+
+// MessageIndexes returns the path of the message within its file: the index of the top level
+// message followed by the indexes of the nested ones.
+func MessageIndexes(md protoreflect.MessageDescriptor) []int {
+	var indexes []int
+	var d protoreflect.Descriptor = md
+	for {
+		if _, ok := d.(protoreflect.FileDescriptor); ok {
+			break
+		}
+		indexes = append([]int{d.Index()}, indexes...)
+		d = d.Parent()
+	}
+	return indexes
+}
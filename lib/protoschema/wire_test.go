@@ -0,0 +1,73 @@
+package protoschema
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAppendHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      int
+		indexes []int
+		want    []byte
+	}{
+		{name: "first message", id: 1, indexes: []int{0}, want: []byte{0, 0, 0, 0, 1, 0}},
+		{name: "second message", id: 2, indexes: []int{1}, want: []byte{0, 0, 0, 0, 2, 2, 2}},
+		{name: "nested message", id: 258, indexes: []int{1, 0}, want: []byte{0, 0, 0, 1, 2, 4, 2, 0}},
+		{name: "large index", id: 3, indexes: []int{64}, want: []byte{0, 0, 0, 0, 3, 2, 0x80, 0x01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AppendHeader(nil, tt.id, tt.indexes)
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("AppendHeader() = %v, want %v", got, tt.want)
+			}
+
+			id, indexes, rest, err := DecodeHeader(append(got, 0x0a, 0x01))
+			if err != nil {
+				t.Fatalf("DecodeHeader() error = %v", err)
+			}
+			if id != tt.id || !equalInts(indexes, tt.indexes) || !bytes.Equal(rest, []byte{0x0a, 0x01}) {
+				t.Fatalf("DecodeHeader() = %d, %v, %v, want %d, %v, [10 1]", id, indexes, rest, tt.id, tt.indexes)
+			}
+		})
+	}
+}
+
+func TestDecodeHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{name: "empty", payload: nil},
+		{name: "bad magic byte", payload: []byte{1, 0, 0, 0, 1, 0}},
+		{name: "truncated id", payload: []byte{0, 0, 0, 1}},
+		{name: "no indexes", payload: []byte{0, 0, 0, 0, 1}},
+		{name: "truncated indexes", payload: []byte{0, 0, 0, 0, 1, 4, 2}},
+		{name: "negative count", payload: []byte{0, 0, 0, 0, 1, 1}},
+		{name: "count beyond payload", payload: []byte{0, 0, 0, 0, 1, 20, 0}},
+		{name: "negative index", payload: []byte{0, 0, 0, 0, 1, 2, 1}},
+		{name: "unterminated varint", payload: []byte{0, 0, 0, 0, 1, 0x80}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := DecodeHeader(tt.payload); !errors.Is(err, ErrInvalidPayload) {
+				t.Fatalf("DecodeHeader() error = %v, want %v", err, ErrInvalidPayload)
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
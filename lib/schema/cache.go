@@ -0,0 +1,304 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riferrei/srclient"
+)
+
+// CachingClient decorates the Schema Registry client with the cache of the schemas by ID and by subject&version,
+// the versions of the subjects and the list of the subjects. The cache lives for the run. The schemas by ID, which
+// are never changed, are optionally persisted to the file to be reused by the next runs until the TTL expires.
+// The versions are not persisted, as the version number is reused after the permanent deletion which may be made
+// by another client. The changes made through the client invalidate the cached subject.
+type CachingClient struct {
+	srclient.ISchemaRegistryClient
+
+	mu       sync.Mutex
+	entries  map[string]*cacheEntry
+	file     *cacheFile
+	path     string
+	registry string
+	dirty    bool
+}
+
+type cacheEntry struct {
+	Stored   time.Time     `json:"stored"`
+	Schema   *cachedSchema `json:"schema,omitempty"`
+	Versions []int         `json:"versions,omitempty"`
+	Subjects []string      `json:"subjects,omitempty"`
+}
+
+type cachedSchema struct {
+	ID         int                  `json:"id"`
+	Schema     string               `json:"schema"`
+	SchemaType srclient.SchemaType  `json:"schemaType"`
+	Version    int                  `json:"version"`
+	References []srclient.Reference `json:"references,omitempty"`
+}
+
+// cacheFile keeps the entries of every registry, so the file is shared by the environments.
+type cacheFile struct {
+	Registries map[string]map[string]*cacheEntry `json:"registries"`
+}
+
+// NewCachingClient creates the caching decorator of the client.
+func NewCachingClient(client srclient.ISchemaRegistryClient) *CachingClient {
+	return &CachingClient{
+		ISchemaRegistryClient: client,
+		entries:               map[string]*cacheEntry{},
+	}
+}
+
+// Load reads the cache of the registry persisted to the file. The entries older than ttl are skipped.
+// The missing or broken file means the empty cache.
+func (c *CachingClient) Load(path, registry string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.path, c.registry = path, registry
+	c.file = &cacheFile{Registries: map[string]map[string]*cacheEntry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can not read registry cache: %w", err)
+	}
+	if err := json.Unmarshal(data, c.file); err != nil || c.file.Registries == nil {
+		c.file.Registries = map[string]map[string]*cacheEntry{}
+		return nil
+	}
+	for key, entry := range c.file.Registries[registry] {
+		if persistent(key) && time.Since(entry.Stored) <= ttl {
+			c.entries[key] = entry
+		}
+	}
+	return nil
+}
+
+// Save writes the cache to the file set by Load. Nothing is written if the cache is not changed.
+func (c *CachingClient) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil || !c.dirty {
+		return nil
+	}
+	entries := map[string]*cacheEntry{}
+	for key, entry := range c.entries {
+		if persistent(key) {
+			entries[key] = entry
+		}
+	}
+	c.file.Registries[c.registry] = entries
+	data, err := json.Marshal(c.file)
+	if err != nil {
+		return fmt.Errorf("can not encode registry cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("can not create registry cache directory: %w", err)
+	}
+	// The file is replaced at once, so the concurrent runs do not read the partial one.
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("can not write registry cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("can not write registry cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("can not write registry cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("can not write registry cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+func (c *CachingClient) GetSubjects() ([]string, error) {
+	if entry := c.get(subjectsKey()); entry != nil {
+		return append([]string(nil), entry.Subjects...), nil
+	}
+	subjects, err := c.ISchemaRegistryClient.GetSubjects()
+	if err != nil {
+		return nil, err
+	}
+	c.put(subjectsKey(), &cacheEntry{Subjects: append([]string(nil), subjects...)})
+	return subjects, nil
+}
+
+func (c *CachingClient) GetSchemaVersions(subject string) ([]int, error) {
+	if entry := c.get(versionsKey(subject)); entry != nil {
+		return append([]int(nil), entry.Versions...), nil
+	}
+	versions, err := c.ISchemaRegistryClient.GetSchemaVersions(subject)
+	if err != nil {
+		return nil, err
+	}
+	c.put(versionsKey(subject), &cacheEntry{Versions: append([]int(nil), versions...)})
+	return versions, nil
+}
+
+func (c *CachingClient) GetSchema(schemaID int) (*srclient.Schema, error) {
+	if schema := c.getSchema(idKey(schemaID)); schema != nil {
+		return schema, nil
+	}
+	schema, err := c.ISchemaRegistryClient.GetSchema(schemaID)
+	if err != nil {
+		return nil, err
+	}
+	c.putSchema(schema, idKey(schemaID))
+	return schema, nil
+}
+
+func (c *CachingClient) GetLatestSchema(subject string) (*srclient.Schema, error) {
+	if schema := c.getSchema(latestKey(subject)); schema != nil {
+		return schema, nil
+	}
+	schema, err := c.ISchemaRegistryClient.GetLatestSchema(subject)
+	if err != nil {
+		return nil, err
+	}
+	c.putSchema(schema, latestKey(subject), versionKey(subject, schema.Version()), idKey(schema.ID()))
+	return schema, nil
+}
+
+func (c *CachingClient) GetSchemaByVersion(subject string, version int) (*srclient.Schema, error) {
+	if schema := c.getSchema(versionKey(subject, version)); schema != nil {
+		return schema, nil
+	}
+	schema, err := c.ISchemaRegistryClient.GetSchemaByVersion(subject, version)
+	if err != nil {
+		return nil, err
+	}
+	c.putSchema(schema, versionKey(subject, version), idKey(schema.ID()))
+	return schema, nil
+}
+
+func (c *CachingClient) CreateSchema(
+	subject string,
+	schema string,
+	schemaType srclient.SchemaType,
+	references ...srclient.Reference,
+) (*srclient.Schema, error) {
+	c.invalidate(subject, false)
+	return c.ISchemaRegistryClient.CreateSchema(subject, schema, schemaType, references...)
+}
+
+func (c *CachingClient) DeleteSubject(subject string, permanent bool) error {
+	c.invalidate(subject, true)
+	return c.ISchemaRegistryClient.DeleteSubject(subject, permanent)
+}
+
+func (c *CachingClient) DeleteSubjectByVersion(subject string, version int, permanent bool) error {
+	c.invalidate(subject, true)
+	return c.ISchemaRegistryClient.DeleteSubjectByVersion(subject, version, permanent)
+}
+
+func (c *CachingClient) ResetCache() {
+	c.mu.Lock()
+	c.entries = map[string]*cacheEntry{}
+	c.dirty = true
+	c.mu.Unlock()
+	c.ISchemaRegistryClient.ResetCache()
+}
+
+func (c *CachingClient) get(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key]
+}
+
+func (c *CachingClient) put(key string, entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.Stored = time.Now()
+	c.entries[key] = entry
+	c.dirty = true
+}
+
+func (c *CachingClient) getSchema(key string) *srclient.Schema {
+	entry := c.get(key)
+	if entry == nil || entry.Schema == nil {
+		return nil
+	}
+	s := entry.Schema
+	schema, err := srclient.NewSchema(s.ID, s.Schema, s.SchemaType, s.Version, s.References, nil, nil)
+	if err != nil {
+		return nil
+	}
+	return schema
+}
+
+func (c *CachingClient) putSchema(schema *srclient.Schema, keys ...string) {
+	cached := &cachedSchema{
+		ID:         schema.ID(),
+		Schema:     schema.Schema(),
+		SchemaType: srclient.Avro,
+		Version:    schema.Version(),
+		References: schema.References(),
+	}
+	if schema.SchemaType() != nil {
+		cached.SchemaType = *schema.SchemaType()
+	}
+	for _, key := range keys {
+		c.put(key, &cacheEntry{Schema: cached})
+	}
+}
+
+// invalidate removes the subject lists and the versions of the subject. The schemas of the versions are removed
+// with the deletion only, as the registration does not change them.
+func (c *CachingClient) invalidate(subject string, versions bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, subjectsKey())
+	delete(c.entries, versionsKey(subject))
+	delete(c.entries, latestKey(subject))
+	if versions {
+		prefix := versionKey(subject, 0)
+		prefix = prefix[:len(prefix)-1]
+		for key := range c.entries {
+			if strings.HasPrefix(key, prefix) {
+				delete(c.entries, key)
+			}
+		}
+	}
+	c.dirty = true
+}
+
+// persistent checks the entry to be kept between the runs.
+func persistent(key string) bool {
+	return strings.HasPrefix(key, "id/")
+}
+
+func subjectsKey() string {
+	return "subjects"
+}
+
+func versionsKey(subject string) string {
+	return "versions/" + subject
+}
+
+func latestKey(subject string) string {
+	return "latest/" + subject
+}
+
+func versionKey(subject string, version int) string {
+	return "version/" + subject + "/" + strconv.Itoa(version)
+}
+
+func idKey(id int) string {
+	return "id/" + strconv.Itoa(id)
+}
@@ -0,0 +1,344 @@
+package schema
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/riferrei/srclient"
+)
+
+// countingClient serves the fixed schemas of the subject "orders-value" and counts the calls.
+type countingClient struct {
+	srclient.ISchemaRegistryClient
+
+	calls    map[string]int
+	versions []int
+}
+
+func newCountingClient() *countingClient {
+	return &countingClient{calls: map[string]int{}, versions: []int{1, 2}}
+}
+
+func (c *countingClient) schema(version int) (*srclient.Schema, error) {
+	return srclient.NewSchema(10+version, "message A {}", srclient.Protobuf, version, nil, nil, nil)
+}
+
+func (c *countingClient) GetSubjects() ([]string, error) {
+	c.calls["GetSubjects"]++
+	return []string{"orders-value"}, nil
+}
+
+func (c *countingClient) GetSchemaVersions(subject string) ([]int, error) {
+	c.calls["GetSchemaVersions"]++
+	if subject != "orders-value" {
+		return nil, srclient.Error{Code: errCodeSubjectNotFound, Message: "Subject not found"}
+	}
+	return c.versions, nil
+}
+
+func (c *countingClient) GetSchema(schemaID int) (*srclient.Schema, error) {
+	c.calls["GetSchema"]++
+	return c.schema(schemaID - 10)
+}
+
+func (c *countingClient) GetLatestSchema(subject string) (*srclient.Schema, error) {
+	c.calls["GetLatestSchema"]++
+	return c.schema(c.versions[len(c.versions)-1])
+}
+
+func (c *countingClient) GetSchemaByVersion(subject string, version int) (*srclient.Schema, error) {
+	c.calls["GetSchemaByVersion"]++
+	return c.schema(version)
+}
+
+func (c *countingClient) CreateSchema(
+	subject string,
+	schema string,
+	schemaType srclient.SchemaType,
+	references ...srclient.Reference,
+) (*srclient.Schema, error) {
+	c.calls["CreateSchema"]++
+	c.versions = append(c.versions, len(c.versions)+1)
+	return c.schema(len(c.versions))
+}
+
+func (c *countingClient) DeleteSubject(subject string, permanent bool) error {
+	c.calls["DeleteSubject"]++
+	c.versions = nil
+	return nil
+}
+
+func (c *countingClient) DeleteSubjectByVersion(subject string, version int, permanent bool) error {
+	c.calls["DeleteSubjectByVersion"]++
+	c.versions = c.versions[:len(c.versions)-1]
+	return nil
+}
+
+func (c *countingClient) ResetCache() {}
+
+func TestCachingClientMemoization(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *CachingClient) (interface{}, error)
+		want interface{}
+	}{
+		{
+			name: "GetSubjects",
+			call: func(c *CachingClient) (interface{}, error) { return c.GetSubjects() },
+			want: []string{"orders-value"},
+		},
+		{
+			name: "GetSchemaVersions",
+			call: func(c *CachingClient) (interface{}, error) { return c.GetSchemaVersions("orders-value") },
+			want: []int{1, 2},
+		},
+		{
+			name: "GetSchema",
+			call: func(c *CachingClient) (interface{}, error) {
+				schema, err := c.GetSchema(11)
+				return schema.ID(), err
+			},
+			want: 11,
+		},
+		{
+			name: "GetLatestSchema",
+			call: func(c *CachingClient) (interface{}, error) {
+				schema, err := c.GetLatestSchema("orders-value")
+				return schema.Version(), err
+			},
+			want: 2,
+		},
+		{
+			name: "GetSchemaByVersion",
+			call: func(c *CachingClient) (interface{}, error) {
+				schema, err := c.GetSchemaByVersion("orders-value", 1)
+				return schema.Version(), err
+			},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newCountingClient()
+			cache := NewCachingClient(client)
+			for i := 0; i < 3; i++ {
+				got, err := tt.call(cache)
+				if err != nil {
+					t.Fatalf("%s() error = %v", tt.name, err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("%s() = %v, want %v", tt.name, got, tt.want)
+				}
+			}
+			if client.calls[tt.name] != 1 {
+				t.Fatalf("%s calls = %d, want 1", tt.name, client.calls[tt.name])
+			}
+		})
+	}
+}
+
+func TestCachingClientLatestSchemaByVersion(t *testing.T) {
+	client := newCountingClient()
+	cache := NewCachingClient(client)
+	if _, err := cache.GetLatestSchema("orders-value"); err != nil {
+		t.Fatalf("GetLatestSchema() error = %v", err)
+	}
+	// The latest schema is cached by its version and ID too.
+	if _, err := cache.GetSchemaByVersion("orders-value", 2); err != nil {
+		t.Fatalf("GetSchemaByVersion() error = %v", err)
+	}
+	if _, err := cache.GetSchema(12); err != nil {
+		t.Fatalf("GetSchema() error = %v", err)
+	}
+	if client.calls["GetSchemaByVersion"] != 0 || client.calls["GetSchema"] != 0 {
+		t.Fatalf("calls = %v, want GetLatestSchema only", client.calls)
+	}
+}
+
+func TestCachingClientInvalidate(t *testing.T) {
+	client := newCountingClient()
+	cache := NewCachingClient(client)
+	warm := func() {
+		t.Helper()
+		if _, err := cache.GetSubjects(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cache.GetSchemaVersions("orders-value"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cache.GetSchemaByVersion("orders-value", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	warm()
+	if _, err := cache.CreateSchema("orders-value", "message A {}", srclient.Protobuf); err != nil {
+		t.Fatalf("CreateSchema() error = %v", err)
+	}
+	versions, err := cache.GetSchemaVersions("orders-value")
+	if err != nil {
+		t.Fatalf("GetSchemaVersions() error = %v", err)
+	}
+	if !reflect.DeepEqual(versions, []int{1, 2, 3}) {
+		t.Fatalf("GetSchemaVersions() = %v, want the registered version", versions)
+	}
+	warm()
+	// The registration keeps the schemas of the versions.
+	want := map[string]int{"GetSubjects": 2, "GetSchemaVersions": 2, "GetSchemaByVersion": 1, "CreateSchema": 1}
+	if !reflect.DeepEqual(client.calls, want) {
+		t.Fatalf("calls = %v, want %v", client.calls, want)
+	}
+
+	if err := cache.DeleteSubjectByVersion("orders-value", 3, false); err != nil {
+		t.Fatalf("DeleteSubjectByVersion() error = %v", err)
+	}
+	warm()
+	want = map[string]int{"GetSubjects": 3, "GetSchemaVersions": 3, "GetSchemaByVersion": 2, "CreateSchema": 1, "DeleteSubjectByVersion": 1}
+	if !reflect.DeepEqual(client.calls, want) {
+		t.Fatalf("calls = %v, want %v", client.calls, want)
+	}
+}
+
+func TestCachingClientPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "registry.json")
+
+	first := NewCachingClient(newCountingClient())
+	if err := first.Load(path, "http://registry", time.Hour); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, err := first.GetSchema(11); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.GetSchemaByVersion("orders-value", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.GetSchemaVersions("orders-value"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		registry string
+		ttl      time.Duration
+		want     map[string]int
+	}{
+		{
+			name:     "schemas by ID",
+			registry: "http://registry",
+			ttl:      time.Hour,
+			want:     map[string]int{"GetSchemaByVersion": 1, "GetSchemaVersions": 1},
+		},
+		{
+			name:     "expired",
+			registry: "http://registry",
+			ttl:      time.Nanosecond,
+			want:     map[string]int{"GetSchema": 2, "GetSchemaByVersion": 1, "GetSchemaVersions": 1},
+		},
+		{
+			name:     "other registry",
+			registry: "http://other",
+			ttl:      time.Hour,
+			want:     map[string]int{"GetSchema": 2, "GetSchemaByVersion": 1, "GetSchemaVersions": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.ttl < time.Millisecond {
+				time.Sleep(time.Millisecond)
+			}
+			client := newCountingClient()
+			cache := NewCachingClient(client)
+			if err := cache.Load(path, tt.registry, tt.ttl); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			for _, id := range []int{11, 12} {
+				schema, err := cache.GetSchema(id)
+				if err != nil {
+					t.Fatalf("GetSchema() error = %v", err)
+				}
+				if schema.ID() != id || schema.Schema() != "message A {}" || *schema.SchemaType() != srclient.Protobuf {
+					t.Fatalf("GetSchema() = %d %q, want the schema %d", schema.ID(), schema.Schema(), id)
+				}
+			}
+			// The versions are loaded from the registry, as they may be deleted and reused by the other clients.
+			if _, err := cache.GetSchemaByVersion("orders-value", 2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := cache.GetSchemaVersions("orders-value"); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(client.calls, tt.want) {
+				t.Fatalf("calls = %v, want %v", client.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachingClientLoadBrokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+	cache := NewCachingClient(newCountingClient())
+	if err := cache.Load(path, "http://registry", time.Hour); err != nil {
+		t.Fatalf("Load() of the missing file error = %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewCachingClient(newCountingClient()).Load(path, "http://registry", time.Hour); err != nil {
+		t.Fatalf("Load() of the broken file error = %v", err)
+	}
+}
+
+func TestSubjectExists(t *testing.T) {
+	errRegistry := errors.New("registry is not available")
+
+	tests := []struct {
+		name    string
+		subject string
+		err     error
+		want    bool
+		wantErr error
+	}{
+		{name: "exists", subject: "orders-value", want: true},
+		{name: "not found", subject: "users-value", want: false},
+		{name: "registry error", subject: "orders-value", err: errRegistry, wantErr: errRegistry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counting := newCountingClient()
+			var client srclient.ISchemaRegistryClient = counting
+			if tt.err != nil {
+				client = failingVersionsClient{ISchemaRegistryClient: client, err: tt.err}
+			}
+			got, err := NewClient(NewCachingClient(client), nil).subjectExists(tt.subject)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("subjectExists() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("subjectExists() = %v, want %v", got, tt.want)
+			}
+			// The subject is probed with its versions instead of listing all subjects.
+			if counting.calls["GetSubjects"] != 0 {
+				t.Fatalf("subjectExists() lists the subjects")
+			}
+		})
+	}
+}
+
+type failingVersionsClient struct {
+	srclient.ISchemaRegistryClient
+	err error
+}
+
+func (c failingVersionsClient) GetSchemaVersions(string) ([]int, error) {
+	return nil, c.err
+}
@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/riferrei/srclient"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+// compile builds the descriptor of the schema together with its references. The schema is compiled
+// as the file with the given name. Imports missing in the references are looked up in the import directories.
+func (c *Client) compile(name, schema string, references []srclient.Reference, importDirs ...string) (*desc.FileDescriptor, error) {
+	files := map[string]string{}
+	if err := c.loadReferences(references, files); err != nil {
+		return nil, err
+	}
+
+	files[name] = schema
+	return protoschema.Compile(name, files, importDirs...)
+}
+
+// loadReferences puts the sources of the references and their own references to the files map.
+func (c *Client) loadReferences(references []srclient.Reference, files map[string]string) error {
+	for _, reference := range references {
+		if _, ok := files[reference.Name]; ok {
+			continue
+		}
+		schema, err := c.schemaRegistryClient.GetSchemaByVersion(reference.Subject, reference.Version)
+		if err != nil {
+			return fmt.Errorf("can not get reference %q: %w", reference.Name, err)
+		}
+		files[reference.Name] = schema.Schema()
+		if err := c.loadReferences(schema.References(), files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordDescriptor finds the message of the record within the compiled schema.
+func (c *Client) recordDescriptor(ctx context.Context, fd *desc.FileDescriptor, schema, record string) (*desc.MessageDescriptor, error) {
+	name, err := c.options.RecordMessage(ctx, []byte(schema), record)
+	if err != nil {
+		return nil, err
+	}
+	if fd.GetPackage() != "" {
+		name = fd.GetPackage() + "." + name
+	}
+	md := fd.FindMessage(name)
+	if md == nil {
+		return nil, fmt.Errorf("message %q not found", name)
+	}
+	return md, nil
+}
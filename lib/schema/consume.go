@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// ConsumeRequest selects the messages of the topic to read. If Last is set, the last messages
+// of every partition are read, otherwise the messages are read from the offset. Reading stops
+// at the end of the partition or when the partition delivers no messages for a while.
+type ConsumeRequest struct {
+	Topic      string
+	FromOffset int64
+	Last       int64
+}
+
+// ConsumeResponse lists the decoded messages.
+type ConsumeResponse struct {
+	Messages []DecodedMessage `json:"messages"`
+}
+
+// Consume reads the messages of the topic and decodes them with the schemas from their payloads.
+// The messages which can not be decoded are returned with the error.
+func (c *Client) Consume(ctx context.Context, request ConsumeRequest) (*ConsumeResponse, error) {
+	decoder := newDecoder(c, request.Topic)
+	response := &ConsumeResponse{Messages: []DecodedMessage{}}
+
+	err := c.readTopic(ctx, request.Topic, request.FromOffset, request.Last, func(m *sarama.ConsumerMessage) {
+		message := DecodedMessage{
+			Partition: m.Partition,
+			Offset:    m.Offset,
+			Key:       string(m.Key),
+		}
+		decoder.decode(ctx, &message, m.Value)
+		response.Messages = append(response.Messages, message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// readTopic calls the handler for the messages of every partition from the offset or for the last
+// messages if last is set. Reading stops at the end of the partition.
+func (c *Client) readTopic(
+	ctx context.Context,
+	topic string,
+	fromOffset, last int64,
+	handler func(*sarama.ConsumerMessage),
+) error {
+	if c.clusterClient == nil {
+		return ErrNoCluster
+	}
+
+	partitions, err := c.clusterClient.Partitions(topic)
+	if err != nil {
+		return fmt.Errorf("can not list partitions of topic %q: %w", topic, err)
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(c.clusterClient)
+	if err != nil {
+		return fmt.Errorf("can not create consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	for _, partition := range partitions {
+		oldest, err := c.clusterClient.GetOffset(topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return fmt.Errorf("can not get oldest offset of partition %d: %w", partition, err)
+		}
+		newest, err := c.clusterClient.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return fmt.Errorf("can not get newest offset of partition %d: %w", partition, err)
+		}
+
+		start := fromOffset
+		if last > 0 {
+			start = newest - last
+		}
+		if start < oldest {
+			start = oldest
+		}
+		if start >= newest {
+			continue
+		}
+
+		if err := readPartition(ctx, consumer, topic, partition, start, newest, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partitionIdleTimeout stops reading the partition which delivers no messages while its end is not reached,
+// e.g. the last offset is the transaction marker or is removed by the compaction.
+var partitionIdleTimeout = 5 * time.Second
+
+// readPartition calls the handler for the messages from the start offset up to the end offset or the high water mark,
+// whichever is reached first. Reading stops if no message is delivered within partitionIdleTimeout.
+func readPartition(
+	ctx context.Context,
+	consumer sarama.Consumer,
+	topic string,
+	partition int32,
+	start, end int64,
+	handler func(*sarama.ConsumerMessage),
+) error {
+	partitionConsumer, err := consumer.ConsumePartition(topic, partition, start)
+	if err != nil {
+		return fmt.Errorf("can not consume partition %d: %w", partition, err)
+	}
+	defer partitionConsumer.Close()
+
+	idle := time.NewTimer(partitionIdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-idle.C:
+			return nil
+		case consumerErr, ok := <-partitionConsumer.Errors():
+			if ok {
+				return fmt.Errorf("can not read partition %d: %w", partition, consumerErr.Err)
+			}
+			return nil
+		case m, ok := <-partitionConsumer.Messages():
+			if !ok {
+				return nil
+			}
+			handler(m)
+			if highWaterMark := partitionConsumer.HighWaterMarkOffset(); m.Offset >= end-1 ||
+				highWaterMark > 0 && m.Offset >= highWaterMark-1 {
+				return nil
+			}
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(partitionIdleTimeout)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+)
+
+func TestReadPartition(t *testing.T) {
+	defer func(timeout time.Duration) { partitionIdleTimeout = timeout }(partitionIdleTimeout)
+	partitionIdleTimeout = 50 * time.Millisecond
+	errBroker := errors.New("broker is not available")
+
+	tests := []struct {
+		name     string
+		messages int
+		end      int64
+		err      error
+		cancel   bool
+		want     []int64
+		wantErr  error
+	}{
+		{name: "end offset", messages: 5, end: 3, want: []int64{1, 2}},
+		{name: "high water mark", messages: 3, end: 100, want: []int64{1, 2, 3}},
+		{name: "idle partition", messages: 0, end: 100},
+		{name: "consumer error", err: errBroker, end: 100, wantErr: errBroker},
+		{name: "cancelled context", cancel: true, end: 100, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consumer := mocks.NewConsumer(t, nil)
+			partitionConsumer := consumer.ExpectConsumePartition("topic", 0, 1)
+			for i := 0; i < tt.messages; i++ {
+				partitionConsumer.YieldMessage(&sarama.ConsumerMessage{})
+			}
+			if tt.err != nil {
+				partitionConsumer.YieldError(tt.err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			var got []int64
+			err := readPartition(ctx, consumer, "topic", 0, 1, tt.end, func(m *sarama.ConsumerMessage) {
+				got = append(got, m.Offset)
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readPartition() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !equalOffsets(got, tt.want) {
+				t.Fatalf("readPartition() offsets = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equalOffsets(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
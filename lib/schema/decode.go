@@ -0,0 +1,165 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/youla-dev/schema/lib/protoschema"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DecodedMessage is the Kafka message decoded with the schema from its payload.
+type DecodedMessage struct {
+	Partition int32           `json:"partition"`
+	Offset    int64           `json:"offset"`
+	Key       string          `json:"key,omitempty"`
+	SchemaID  int             `json:"schema_id,omitempty"`
+	Subject   string          `json:"subject,omitempty"`
+	Version   int             `json:"version,omitempty"`
+	Record    string          `json:"record,omitempty"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// decoder decodes the payloads in the Confluent wire format. Schemas are loaded and compiled
+// once per schema ID.
+type decoder struct {
+	client *Client
+	topic  string
+
+	mu      sync.Mutex
+	schemas map[int]*decoderSchema
+}
+
+type decoderSchema struct {
+	file     *desc.FileDescriptor
+	subject  SubjectVersion
+	subjects []SubjectVersion
+	checkErr error
+}
+
+// usedBy checks the schema to be registered as the version of the subject.
+func (s *decoderSchema) usedBy(subject string) bool {
+	for _, version := range s.subjects {
+		if version.Subject == subject {
+			return true
+		}
+	}
+	return false
+}
+
+func newDecoder(client *Client, topic string) *decoder {
+	return &decoder{
+		client:  client,
+		topic:   topic,
+		schemas: make(map[int]*decoderSchema),
+	}
+}
+
+// decode fills the message with the schema and the value decoded from the payload.
+// The decoding error is put to the message.
+func (d *decoder) decode(ctx context.Context, message *DecodedMessage, payload []byte) {
+	decoded, md, err := d.decodeMessage(ctx, message, payload)
+	if err != nil {
+		message.Error = err.Error()
+		return
+	}
+	message.Record = md.GetFullyQualifiedName()
+
+	value, err := protojson.Marshal(decoded)
+	if err != nil {
+		message.Error = fmt.Sprintf("can not convert message to json: %v", err)
+		return
+	}
+	message.Value = value
+}
+
+func (d *decoder) decodeMessage(
+	ctx context.Context,
+	message *DecodedMessage,
+	payload []byte,
+) (*dynamicpb.Message, *desc.MessageDescriptor, error) {
+	schemaID, indexes, body, err := protoschema.DecodeHeader(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	message.SchemaID = schemaID
+
+	schema, err := d.schema(ctx, schemaID)
+	if err != nil {
+		return nil, nil, err
+	}
+	message.Subject = schema.subject.Subject
+	message.Version = schema.subject.Version
+
+	md, err := messageByIndexes(schema.file, indexes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("schema %d: %w", schemaID, err)
+	}
+
+	decoded := dynamicpb.NewMessage(md.UnwrapMessage())
+	if err := proto.Unmarshal(body, decoded); err != nil {
+		return nil, md, fmt.Errorf("can not unmarshal %q: %w", md.GetFullyQualifiedName(), err)
+	}
+	return decoded, md, nil
+}
+
+func (d *decoder) schema(ctx context.Context, schemaID int) (*decoderSchema, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if schema, ok := d.schemas[schemaID]; ok {
+		return schema, schema.checkErr
+	}
+
+	schema := &decoderSchema{}
+	schema.checkErr = d.loadSchema(ctx, schemaID, schema)
+	d.schemas[schemaID] = schema
+	return schema, schema.checkErr
+}
+
+func (d *decoder) loadSchema(ctx context.Context, schemaID int, schema *decoderSchema) error {
+	if d.client.registry == nil {
+		return ErrNoRegistry
+	}
+	registered, err := d.client.registry.GetSchemaByID(ctx, schemaID)
+	if err != nil {
+		return fmt.Errorf("can not get schema %d: %w", schemaID, err)
+	}
+	schema.subjects, err = d.client.registry.GetSubjectVersionsByID(ctx, schemaID)
+	if err != nil {
+		return fmt.Errorf("can not get subjects of schema %d: %w", schemaID, err)
+	}
+
+	for _, version := range schema.subjects {
+		if schema.subject.Subject == "" || strings.HasPrefix(version.Subject, d.topic+"-") {
+			schema.subject = version
+		}
+	}
+
+	schema.file, err = d.client.compile(fmt.Sprintf("schema-%d.proto", schemaID), registered.Schema, registered.References)
+	return err
+}
+
+// messageByIndexes walks the nested messages of the file along the message indexes.
+func messageByIndexes(fd *desc.FileDescriptor, indexes []int) (*desc.MessageDescriptor, error) {
+	messages := fd.GetMessageTypes()
+	var md *desc.MessageDescriptor
+	for _, index := range indexes {
+		if index >= len(messages) {
+			return nil, fmt.Errorf("message index %v not found", indexes)
+		}
+		md = messages[index]
+		messages = md.GetNestedMessageTypes()
+	}
+	if md == nil {
+		return nil, fmt.Errorf("message index %v not found", indexes)
+	}
+	return md, nil
+}
@@ -0,0 +1,171 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrProtected is returned for the deletion of the protected subject.
+	ErrProtected = errors.New("subject is protected")
+	// ErrNotSoftDeleted is returned for the permanent deletion of the version which is not soft-deleted.
+	ErrNotSoftDeleted = errors.New("schema must be soft-deleted before the permanent deletion")
+)
+
+// DeleteRequest describes the version to delete. The zero version deletes the whole subject.
+// The permanent deletion is allowed only for the soft-deleted versions.
+// Before the deletion the versions are checked not to be referenced by other schemas and not to be
+// used by the last ScanWindow messages of every topic partition. The topic data is not scanned if the client
+// has no cluster. The usage in the topic data is ignored with Force, the references are never ignored.
+type DeleteRequest struct {
+	Topic      string
+	Record     string
+	Version    int
+	Permanent  bool
+	Force      bool
+	ScanWindow int64
+}
+
+// DeletePlan lists the versions to be removed by the deletion.
+type DeletePlan struct {
+	Subject   string       `json:"subject"`
+	Version   int          `json:"version,omitempty"`
+	Versions  []int        `json:"versions"`
+	Permanent bool         `json:"permanent"`
+	Usage     *UsageReport `json:"usage,omitempty"`
+}
+
+func (p *DeletePlan) String() string {
+	mode := "soft"
+	if p.Permanent {
+		mode = "permanent"
+	}
+	target := fmt.Sprintf("versions %v of subject %q", p.Versions, p.Subject)
+	if p.Version == 0 {
+		target = fmt.Sprintf("subject %q with versions %v", p.Subject, p.Versions)
+	}
+	lines := []string{fmt.Sprintf("The %s deletion of %s", mode, target)}
+	if p.Usage != nil && p.Usage.Scanned > 0 {
+		lines = append(lines, p.Usage.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DeleteResponse is the result of the deletion.
+type DeleteResponse DeletePlan
+
+func (r *DeleteResponse) String() string {
+	mode := "Soft-deleted"
+	if r.Permanent {
+		mode = "Permanently deleted"
+	}
+	if r.Version == 0 {
+		return fmt.Sprintf("%s subject %q with versions %v", mode, r.Subject, r.Versions)
+	}
+	return fmt.Sprintf("%s versions %v of subject %q", mode, r.Versions, r.Subject)
+}
+
+// Delete removes the version of the subject or the whole subject. InUseError is returned
+// if the versions are still used.
+func (c *Client) Delete(ctx context.Context, request DeleteRequest) (*DeleteResponse, error) {
+	plan, err := c.PlanDelete(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return c.ApplyDelete(ctx, plan)
+}
+
+// PlanDelete checks the deletion to be allowed and lists the versions to remove.
+// ErrProtected, ErrNotSoftDeleted or InUseError are returned for the forbidden deletion.
+func (c *Client) PlanDelete(ctx context.Context, request DeleteRequest) (*DeletePlan, error) {
+	if c.registry == nil {
+		return nil, ErrNoRegistry
+	}
+	subject := SubjectName(request.Topic, request.Record)
+	for _, pattern := range c.protectedSubjects {
+		if pattern.MatchString(subject) {
+			return nil, fmt.Errorf("%w: %q matches %q", ErrProtected, subject, pattern)
+		}
+	}
+
+	active, all, err := c.allVersions(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := deleteVersions(request, subject, active, all)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := c.usage(ctx, request.Topic, subject, versions, request.ScanWindow)
+	if err != nil {
+		return nil, fmt.Errorf("can not check schema usage: %w", err)
+	}
+	if report.Referenced() || (report.InUse() && !request.Force) {
+		return nil, &InUseError{Subject: subject, Report: report}
+	}
+
+	return &DeletePlan{
+		Subject:   subject,
+		Version:   request.Version,
+		Versions:  versions,
+		Permanent: request.Permanent,
+		Usage:     report,
+	}, nil
+}
+
+// deleteVersions selects the versions to delete. The soft deletion removes the active versions,
+// the permanent one removes the soft-deleted versions.
+func deleteVersions(request DeleteRequest, subject string, active, all []int) ([]int, error) {
+	if request.Version == 0 {
+		switch {
+		case request.Permanent && len(active) > 0:
+			return nil, fmt.Errorf("%w: delete subject %q without --permanent first", ErrNotSoftDeleted, subject)
+		case request.Permanent:
+			return all, nil
+		case len(active) == 0:
+			return nil, fmt.Errorf("subject %q is already soft-deleted, use --permanent to remove it", subject)
+		}
+		return active, nil
+	}
+
+	switch {
+	case !containsInt(all, request.Version):
+		return nil, fmt.Errorf("version %d of subject %q not exist", request.Version, subject)
+	case request.Permanent && containsInt(active, request.Version):
+		return nil, fmt.Errorf("%w: delete version %d of subject %q without --permanent first", ErrNotSoftDeleted, request.Version, subject)
+	case !request.Permanent && !containsInt(active, request.Version):
+		return nil, fmt.Errorf("version %d of subject %q is already soft-deleted, use --permanent to remove it", request.Version, subject)
+	}
+	return []int{request.Version}, nil
+}
+
+// ApplyDelete removes the versions of the plan.
+func (c *Client) ApplyDelete(ctx context.Context, plan *DeletePlan) (*DeleteResponse, error) {
+	if c.registry == nil {
+		return nil, ErrNoRegistry
+	}
+
+	var err error
+	if plan.Version == 0 {
+		_, err = c.registry.DeleteSubject(ctx, plan.Subject, plan.Permanent)
+	} else {
+		err = c.registry.DeleteSubjectVersion(ctx, plan.Subject, plan.Version, plan.Permanent)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not delete: %w", err)
+	}
+	return (*DeleteResponse)(plan), nil
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDeleteResponseString(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *DeleteResponse
+		want     string
+	}{
+		{
+			name:     "subject",
+			response: &DeleteResponse{Subject: "orders-Order-value", Versions: []int{1, 2}},
+			want:     `Soft-deleted subject "orders-Order-value" with versions [1 2]`,
+		},
+		{
+			name:     "permanent version",
+			response: &DeleteResponse{Subject: "orders-Order-value", Version: 2, Versions: []int{2}, Permanent: true},
+			want:     `Permanently deleted versions [2] of subject "orders-Order-value"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The text output renders the fmt.Stringer values.
+			var stringer fmt.Stringer = tt.response
+			if got := stringer.String(); got != tt.want {
+				t.Fatalf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
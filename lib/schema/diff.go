@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Kinds of the schema changes.
+const (
+	ChangeFieldAdded        = "field_added"
+	ChangeFieldRemoved      = "field_removed"
+	ChangeFieldRenamed      = "field_renamed"
+	ChangeFieldTypeChanged  = "field_type_changed"
+	ChangeFieldLabelChanged = "field_label_changed"
+)
+
+// Change is the semantic change of the field between the registered and the proposed schema. Old and New hold
+// the changed value: the name, the type or the label, e.g. optional or repeated.
+type Change struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Field   string `json:"field"`
+	Number  int32  `json:"number"`
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeFieldAdded:
+		return fmt.Sprintf("field %s = %d is added to %s", c.Field, c.Number, c.Message)
+	case ChangeFieldRemoved:
+		return fmt.Sprintf("field %s = %d is removed from %s", c.Field, c.Number, c.Message)
+	}
+	property := map[string]string{
+		ChangeFieldRenamed:      "name",
+		ChangeFieldTypeChanged:  "type",
+		ChangeFieldLabelChanged: "label",
+	}[c.Kind]
+	return fmt.Sprintf("field %s = %d of %s: %s is changed from %q to %q", c.Field, c.Number, c.Message, property, c.Old, c.New)
+}
+
+// DiffMessages lists the changes of the fields of the message and of the messages used by its fields.
+// The fields are matched by the numbers.
+func DiffMessages(registered, proposed *desc.MessageDescriptor) []Change {
+	changes := []Change{}
+	diffMessages(registered, proposed, map[string]bool{}, &changes)
+	return changes
+}
+
+func diffMessages(registered, proposed *desc.MessageDescriptor, visited map[string]bool, changes *[]Change) {
+	if visited[registered.GetFullyQualifiedName()] {
+		return
+	}
+	visited[registered.GetFullyQualifiedName()] = true
+
+	message := proposed.GetFullyQualifiedName()
+	for _, field := range registered.GetFields() {
+		proposedField := proposed.FindFieldByNumber(field.GetNumber())
+		if proposedField == nil {
+			*changes = append(*changes, Change{
+				Kind:    ChangeFieldRemoved,
+				Message: message,
+				Field:   field.GetName(),
+				Number:  field.GetNumber(),
+				Old:     field.GetName(),
+			})
+			continue
+		}
+
+		change := Change{Message: message, Field: proposedField.GetName(), Number: field.GetNumber()}
+		if field.GetName() != proposedField.GetName() {
+			change.Kind, change.Old, change.New = ChangeFieldRenamed, field.GetName(), proposedField.GetName()
+			*changes = append(*changes, change)
+		}
+		if oldType, newType := fieldType(field), fieldType(proposedField); oldType != newType {
+			change.Kind, change.Old, change.New = ChangeFieldTypeChanged, oldType, newType
+			*changes = append(*changes, change)
+		} else if field.GetMessageType() != nil {
+			diffMessages(field.GetMessageType(), proposedField.GetMessageType(), visited, changes)
+		}
+		if oldLabel, newLabel := fieldLabel(field), fieldLabel(proposedField); oldLabel != newLabel {
+			change.Kind, change.Old, change.New = ChangeFieldLabelChanged, oldLabel, newLabel
+			*changes = append(*changes, change)
+		}
+	}
+
+	for _, field := range proposed.GetFields() {
+		if registered.FindFieldByNumber(field.GetNumber()) == nil {
+			*changes = append(*changes, Change{
+				Kind:    ChangeFieldAdded,
+				Message: message,
+				Field:   field.GetName(),
+				Number:  field.GetNumber(),
+				New:     field.GetName(),
+			})
+		}
+	}
+}
+
+// fieldType returns the full name of the message or enum type or the scalar type name, e.g. int32.
+func fieldType(field *desc.FieldDescriptor) string {
+	if field.GetMessageType() != nil {
+		return field.GetMessageType().GetFullyQualifiedName()
+	}
+	if field.GetEnumType() != nil {
+		return field.GetEnumType().GetFullyQualifiedName()
+	}
+	return strings.ToLower(strings.TrimPrefix(field.GetType().String(), "TYPE_"))
+}
+
+// fieldLabel returns repeated, required, optional for the fields with the presence or singular otherwise.
+func fieldLabel(field *desc.FieldDescriptor) string {
+	switch {
+	case field.IsRepeated():
+		return "repeated"
+	case field.IsRequired():
+		return "required"
+	case field.IsProto3Optional(), field.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL && !field.GetFile().IsProto3():
+		return "optional"
+	}
+	return "singular"
+}
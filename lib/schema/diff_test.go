@@ -0,0 +1,173 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+func compileMessage(t *testing.T, schema, message string) *desc.MessageDescriptor {
+	t.Helper()
+	fd, err := protoschema.Compile("diff.proto", map[string]string{"diff.proto": schema})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	md := fd.FindMessage(message)
+	if md == nil {
+		t.Fatalf("message %q is not found", message)
+	}
+	return md
+}
+
+func TestDiffMessages(t *testing.T) {
+	const registered = `syntax = "proto3";
+package diff;
+
+message Order {
+  string id = 1;
+  int64 price = 2;
+  string sum = 3;
+  Item item = 4;
+  string comment = 5;
+  int32 count = 6;
+}
+
+message Item {
+  string name = 1;
+  int32 amount = 2;
+}
+`
+
+	tests := []struct {
+		name     string
+		proposed string
+		want     []Change
+	}{
+		{name: "no changes", proposed: registered, want: []Change{}},
+		{
+			name: "field changes",
+			proposed: `syntax = "proto3";
+package diff;
+
+message Order {
+  string id = 1;
+  double price = 2;
+  string total = 3;
+  Item item = 4;
+  optional int32 count = 6;
+  repeated string tags = 7;
+}
+
+message Item {
+  string title = 1;
+  repeated int32 amount = 2;
+}
+`,
+			want: []Change{
+				{Kind: ChangeFieldTypeChanged, Message: "diff.Order", Field: "price", Number: 2, Old: "int64", New: "double"},
+				{Kind: ChangeFieldRenamed, Message: "diff.Order", Field: "total", Number: 3, Old: "sum", New: "total"},
+				{Kind: ChangeFieldRenamed, Message: "diff.Item", Field: "title", Number: 1, Old: "name", New: "title"},
+				{Kind: ChangeFieldLabelChanged, Message: "diff.Item", Field: "amount", Number: 2, Old: "singular", New: "repeated"},
+				{Kind: ChangeFieldRemoved, Message: "diff.Order", Field: "comment", Number: 5, Old: "comment"},
+				{Kind: ChangeFieldLabelChanged, Message: "diff.Order", Field: "count", Number: 6, Old: "singular", New: "optional"},
+				{Kind: ChangeFieldAdded, Message: "diff.Order", Field: "tags", Number: 7, New: "tags"},
+			},
+		},
+		{
+			name: "message type changed",
+			proposed: `syntax = "proto3";
+package diff;
+
+message Order {
+  string id = 1;
+  int64 price = 2;
+  string sum = 3;
+  Product item = 4;
+  string comment = 5;
+  int32 count = 6;
+}
+
+message Item {
+  string name = 1;
+  int32 amount = 2;
+}
+
+message Product {
+  string sku = 1;
+}
+`,
+			want: []Change{
+				{Kind: ChangeFieldTypeChanged, Message: "diff.Order", Field: "item", Number: 4, Old: "diff.Item", New: "diff.Product"},
+			},
+		},
+		{
+			name: "renamed and retyped",
+			proposed: `syntax = "proto3";
+package diff;
+
+message Order {
+  string id = 1;
+  int64 price = 2;
+  repeated bytes summary = 3;
+  Item item = 4;
+  string comment = 5;
+  int32 count = 6;
+}
+
+message Item {
+  string name = 1;
+  int32 amount = 2;
+}
+`,
+			want: []Change{
+				{Kind: ChangeFieldRenamed, Message: "diff.Order", Field: "summary", Number: 3, Old: "sum", New: "summary"},
+				{Kind: ChangeFieldTypeChanged, Message: "diff.Order", Field: "summary", Number: 3, Old: "string", New: "bytes"},
+				{Kind: ChangeFieldLabelChanged, Message: "diff.Order", Field: "summary", Number: 3, Old: "singular", New: "repeated"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffMessages(compileMessage(t, registered, "diff.Order"), compileMessage(t, tt.proposed, "diff.Order"))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("DiffMessages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffMessagesRecursive(t *testing.T) {
+	const schema = `syntax = "proto3";
+package diff;
+
+message Node {
+  string name = 1;
+  repeated Node children = 2;
+}
+`
+	got := DiffMessages(compileMessage(t, schema, "diff.Node"), compileMessage(t, schema, "diff.Node"))
+	if len(got) != 0 {
+		t.Fatalf("DiffMessages() = %v, want no changes", got)
+	}
+}
+
+func TestFieldLabel(t *testing.T) {
+	const schema = `syntax = "proto2";
+package diff;
+
+message Legacy {
+  required string id = 1;
+  optional string name = 2;
+  repeated string tags = 3;
+}
+`
+	md := compileMessage(t, schema, "diff.Legacy")
+	want := map[string]string{"id": "required", "name": "optional", "tags": "repeated"}
+	for field, label := range want {
+		if got := fieldLabel(md.FindFieldByName(field)); got != label {
+			t.Fatalf("fieldLabel(%s) = %q, want %q", field, got, label)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/youla-dev/schema/lib/protoschema"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ExportRequest selects the version of the subject. The zero version means the latest one.
+// The schema is compiled to the serialized FileDescriptorSet if DescriptorSet is set,
+// the source code info is kept in the set if SourceInfo is set.
+type ExportRequest struct {
+	Topic         string
+	Record        string
+	Version       int
+	DescriptorSet bool
+	SourceInfo    bool
+}
+
+// ExportResponse holds the schema value of the version. DescriptorSet holds the schema and its references
+// compiled to the serialized FileDescriptorSet, the dependencies go before the files importing them.
+type ExportResponse struct {
+	Subject       string `json:"subject"`
+	ID            int    `json:"id"`
+	Version       int    `json:"version"`
+	Schema        string `json:"schema"`
+	DescriptorSet []byte `json:"-"`
+}
+
+// Export loads the schema value of the version. ErrSubjectNotExist is returned for the unknown subject.
+func (c *Client) Export(ctx context.Context, request ExportRequest) (*ExportResponse, error) {
+	subject := SubjectName(request.Topic, request.Record)
+
+	schema, err := c.getSchema(subject, request.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ExportResponse{
+		Subject: subject,
+		ID:      schema.ID(),
+		Version: schema.Version(),
+		Schema:  schema.Schema(),
+	}
+	if !request.DescriptorSet {
+		return response, nil
+	}
+
+	files := map[string]string{}
+	if err := c.loadReferences(schema.References(), files); err != nil {
+		return nil, err
+	}
+	name := subject + ".proto"
+	files[name] = schema.Schema()
+	compile := protoschema.Compile
+	if request.SourceInfo {
+		compile = protoschema.CompileWithSourceInfo
+	}
+	fd, err := compile(name, files)
+	if err != nil {
+		return nil, err
+	}
+	response.DescriptorSet, err = proto.MarshalOptions{Deterministic: true}.Marshal(descriptorSet(fd))
+	if err != nil {
+		return nil, fmt.Errorf("can not marshal descriptor set: %w", err)
+	}
+	return response, nil
+}
+
+// descriptorSet collects the file and its transitive imports into the set in the topological order.
+func descriptorSet(fd *desc.FileDescriptor) *descriptorpb.FileDescriptorSet {
+	set := &descriptorpb.FileDescriptorSet{}
+	added := map[string]bool{}
+	var add func(fd *desc.FileDescriptor)
+	add = func(fd *desc.FileDescriptor) {
+		if added[fd.GetName()] {
+			return
+		}
+		added[fd.GetName()] = true
+		for _, dep := range fd.GetDependencies() {
+			add(dep)
+		}
+		set.File = append(set.File, fd.AsFileDescriptorProto())
+	}
+	add(fd)
+	return set
+}
@@ -0,0 +1,401 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+// GraphRequest selects the source of the reference graph. The graph is built from the imports of the local
+// proto files found in the ProtoPaths if any, otherwise from the references of all subject versions of the registry.
+// DependsOn limits the graph to the nodes depending on the node directly or transitively, PullsIn limits it
+// to the nodes the node depends on. Both match the node ID or the subject.
+type GraphRequest struct {
+	ProtoPaths []string
+	DependsOn  string
+	PullsIn    string
+}
+
+// Graph is the graph of the schema references. Edges lead from the schema to its references.
+type Graph struct {
+	Nodes    []GraphNode `json:"nodes"`
+	Edges    []GraphEdge `json:"edges"`
+	Cycles   [][]string  `json:"cycles,omitempty"`
+	Dangling []GraphEdge `json:"dangling,omitempty"`
+}
+
+// GraphNode is the subject version, identified as "subject@version", or the local proto file identified by
+// its import path. Builtin marks the well-known google/protobuf imports, Missing marks the targets of
+// the dangling references.
+type GraphNode struct {
+	ID      string `json:"id"`
+	Subject string `json:"subject,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Builtin bool   `json:"builtin,omitempty"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+// GraphEdge is the reference of the schema by its import name.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Name string `json:"name"`
+}
+
+// Graph builds the reference graph and finds the cycles and the dangling references.
+func (c *Client) Graph(ctx context.Context, request GraphRequest) (*Graph, error) {
+	var (
+		g   *Graph
+		err error
+	)
+	if len(request.ProtoPaths) > 0 {
+		g, err = localGraph(request.ProtoPaths)
+	} else {
+		g, err = c.registryGraph(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if request.DependsOn != "" {
+		if g, err = g.reachable(request.DependsOn, true); err != nil {
+			return nil, err
+		}
+	}
+	if request.PullsIn != "" {
+		if g, err = g.reachable(request.PullsIn, false); err != nil {
+			return nil, err
+		}
+	}
+	g.Cycles = g.cycles()
+	return g, nil
+}
+
+func graphNodeID(subject string, version int) string {
+	return fmt.Sprintf("%s@%d", subject, version)
+}
+
+func (c *Client) registryGraph(ctx context.Context) (*Graph, error) {
+	if c.registry == nil {
+		return nil, ErrNoRegistry
+	}
+	subjects, err := c.schemaRegistryClient.GetSubjects()
+	if err != nil {
+		return nil, fmt.Errorf("can not get subjects: %w", err)
+	}
+
+	g := newGraph()
+	for _, subject := range subjects {
+		versions, err := c.registry.GetVersions(ctx, subject, false)
+		if err != nil {
+			return nil, fmt.Errorf("can not get versions of subject %q: %w", subject, err)
+		}
+		for _, version := range versions {
+			schema, err := c.registry.GetSchemaByVersion(ctx, subject, version, false)
+			if err != nil {
+				return nil, fmt.Errorf("can not get version %d of subject %q: %w", version, subject, err)
+			}
+			id := graphNodeID(subject, version)
+			g.addNode(GraphNode{ID: id, Subject: subject, Version: version})
+			for _, reference := range schema.References {
+				g.addEdge(GraphEdge{
+					From: id,
+					To:   graphNodeID(reference.Subject, reference.Version),
+					Name: reference.Name,
+				}, GraphNode{Subject: reference.Subject, Version: reference.Version})
+			}
+		}
+	}
+	return g.build(), nil
+}
+
+func localGraph(protoPaths []string) (*Graph, error) {
+	g := newGraph()
+	for _, root := range protoPaths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || filepath.Ext(path) != ".proto" {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			id := filepath.ToSlash(rel)
+			if _, ok := g.nodes[id]; ok {
+				return nil
+			}
+			protobuf, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			imports, err := protoschema.Imports(protobuf)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			g.addNode(GraphNode{ID: id})
+			for _, name := range imports {
+				g.addEdge(GraphEdge{From: id, To: name, Name: name}, GraphNode{
+					Builtin: strings.HasPrefix(name, "google/protobuf/"),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("can not read proto files: %w", err)
+		}
+	}
+	return g.build(), nil
+}
+
+// graphBuilder collects the nodes and the edges. The edge targets are kept to add the builtin
+// and the missing nodes.
+type graphBuilder struct {
+	nodes   map[string]GraphNode
+	edges   []GraphEdge
+	targets map[string]GraphNode
+}
+
+func newGraph() *graphBuilder {
+	return &graphBuilder{
+		nodes:   map[string]GraphNode{},
+		targets: map[string]GraphNode{},
+	}
+}
+
+func (b *graphBuilder) addNode(node GraphNode) {
+	b.nodes[node.ID] = node
+}
+
+func (b *graphBuilder) addEdge(edge GraphEdge, target GraphNode) {
+	target.ID = edge.To
+	b.edges = append(b.edges, edge)
+	b.targets[edge.To] = target
+}
+
+func (b *graphBuilder) build() *Graph {
+	g := &Graph{Edges: b.edges}
+	for id, target := range b.targets {
+		if _, ok := b.nodes[id]; ok {
+			continue
+		}
+		if !target.Builtin {
+			target.Missing = true
+		}
+		b.nodes[id] = target
+	}
+	for _, node := range b.nodes {
+		g.Nodes = append(g.Nodes, node)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	for _, edge := range g.Edges {
+		if b.nodes[edge.To].Missing {
+			g.Dangling = append(g.Dangling, edge)
+		}
+	}
+	return g
+}
+
+// reachable returns the subgraph of the nodes reachable from the matching nodes. The edges are followed
+// backwards with reverse, so the nodes depending on the matching ones are found.
+func (g *Graph) reachable(query string, reverse bool) (*Graph, error) {
+	adjacent := map[string][]string{}
+	for _, edge := range g.Edges {
+		if reverse {
+			adjacent[edge.To] = append(adjacent[edge.To], edge.From)
+		} else {
+			adjacent[edge.From] = append(adjacent[edge.From], edge.To)
+		}
+	}
+
+	visited := map[string]bool{}
+	var queue []string
+	for _, node := range g.Nodes {
+		if node.ID == query || node.Subject == query {
+			visited[node.ID] = true
+			queue = append(queue, node.ID)
+		}
+	}
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("node %q not found in the graph", query)
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacent[id] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	subgraph := &Graph{}
+	for _, node := range g.Nodes {
+		if visited[node.ID] {
+			subgraph.Nodes = append(subgraph.Nodes, node)
+		}
+	}
+	for _, edge := range g.Edges {
+		if visited[edge.From] && visited[edge.To] {
+			subgraph.Edges = append(subgraph.Edges, edge)
+		}
+	}
+	for _, edge := range g.Dangling {
+		if visited[edge.From] && visited[edge.To] {
+			subgraph.Dangling = append(subgraph.Dangling, edge)
+		}
+	}
+	return subgraph, nil
+}
+
+// cycles finds the strongly connected components of more than one node and the nodes referencing themselves.
+func (g *Graph) cycles() [][]string {
+	adjacent := map[string][]string{}
+	for _, edge := range g.Edges {
+		adjacent[edge.From] = append(adjacent[edge.From], edge.To)
+	}
+
+	var (
+		index   int
+		indexes = map[string]int{}
+		lowest  = map[string]int{}
+		onStack = map[string]bool{}
+		stack   []string
+		cycles  [][]string
+		visit   func(id string)
+	)
+	visit = func(id string) {
+		indexes[id] = index
+		lowest[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		selfReference := false
+		for _, next := range adjacent[id] {
+			if next == id {
+				selfReference = true
+			}
+			if _, ok := indexes[next]; !ok {
+				visit(next)
+				if lowest[next] < lowest[id] {
+					lowest[id] = lowest[next]
+				}
+			} else if onStack[next] && indexes[next] < lowest[id] {
+				lowest[id] = indexes[next]
+			}
+		}
+		if lowest[id] != indexes[id] {
+			return
+		}
+
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == id {
+				break
+			}
+		}
+		if len(component) > 1 || selfReference {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, node := range g.Nodes {
+		if _, ok := indexes[node.ID]; !ok {
+			visit(node.ID)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
+
+// DOT renders the graph in the Graphviz format. The missing nodes and the cycles are highlighted.
+func (g *Graph) DOT() string {
+	inCycle := g.inCycle()
+	var b strings.Builder
+	b.WriteString("digraph schema {\n")
+	for _, node := range g.Nodes {
+		var attributes []string
+		switch {
+		case node.Missing:
+			attributes = append(attributes, "color=red", "style=dashed")
+		case node.Builtin:
+			attributes = append(attributes, "color=gray")
+		case inCycle[node.ID]:
+			attributes = append(attributes, "color=orange")
+		}
+		if len(attributes) > 0 {
+			fmt.Fprintf(&b, "\t%q [%s];\n", node.ID, strings.Join(attributes, ", "))
+		} else {
+			fmt.Fprintf(&b, "\t%q;\n", node.ID)
+		}
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "\t%q -> %q [label=%q];\n", edge.From, edge.To, edge.Name)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as the Mermaid flowchart. The missing nodes and the cycles are highlighted.
+func (g *Graph) Mermaid() string {
+	inCycle := g.inCycle()
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, node := range g.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "\t%s[\"%s\"]\n", ids[node.ID], mermaidEscape(node.ID))
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -->|\"%s\"| %s\n", ids[edge.From], mermaidEscape(edge.Name), ids[edge.To])
+	}
+	b.WriteString("\tclassDef missing stroke:red,stroke-dasharray:5\n")
+	b.WriteString("\tclassDef cycle stroke:orange\n")
+	for _, node := range g.Nodes {
+		switch {
+		case node.Missing:
+			fmt.Fprintf(&b, "\tclass %s missing\n", ids[node.ID])
+		case inCycle[node.ID]:
+			fmt.Fprintf(&b, "\tclass %s cycle\n", ids[node.ID])
+		}
+	}
+	return b.String()
+}
+
+func (g *Graph) inCycle() map[string]bool {
+	inCycle := map[string]bool{}
+	for _, cycle := range g.Cycles {
+		for _, id := range cycle {
+			inCycle[id] = true
+		}
+	}
+	return inCycle
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
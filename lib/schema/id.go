@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/riferrei/srclient"
+)
+
+// SchemaByIDRequest selects the schema by its ID, e.g. taken from the wire format.
+type SchemaByIDRequest struct {
+	ID int
+}
+
+// SchemaByIDResponse describes the schema and all subject versions using it.
+type SchemaByIDResponse struct {
+	ID         int                  `json:"id"`
+	SchemaType string               `json:"schema_type"`
+	References []srclient.Reference `json:"references"`
+	Subjects   []SchemaIDSubject    `json:"subjects"`
+	Schema     string               `json:"schema"`
+}
+
+// SchemaIDSubject is the subject version using the schema. Topic and record are set if the subject
+// is named according to TopicRecordNameStrategy.
+type SchemaIDSubject struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	Topic   string `json:"topic,omitempty"`
+	Record  string `json:"record,omitempty"`
+}
+
+// SchemaByID loads the schema by its ID and lists the subject versions using it.
+func (c *Client) SchemaByID(ctx context.Context, request SchemaByIDRequest) (*SchemaByIDResponse, error) {
+	if c.registry == nil {
+		return nil, ErrNoRegistry
+	}
+	schema, err := c.registry.GetSchemaByID(ctx, request.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can not get schema %d: %w", request.ID, err)
+	}
+	versions, err := c.registry.GetSubjectVersionsByID(ctx, request.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can not get subjects of schema %d: %w", request.ID, err)
+	}
+
+	response := &SchemaByIDResponse{
+		ID:         request.ID,
+		SchemaType: schema.SchemaType,
+		References: schema.References,
+		Subjects:   make([]SchemaIDSubject, 0, len(versions)),
+		Schema:     schema.Schema,
+	}
+	// The registry omits the type of Avro schemas.
+	if response.SchemaType == "" {
+		response.SchemaType = srclient.Avro.String()
+	}
+	for _, version := range versions {
+		subject := SchemaIDSubject{
+			Subject: version.Subject,
+			Version: version.Version,
+		}
+		subject.Topic, subject.Record, _ = ParseSubjectName(version.Subject)
+		response.Subjects = append(response.Subjects, subject)
+	}
+	return response, nil
+}
@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/riferrei/srclient"
+)
+
+// InspectRequest selects the version of the subject. The zero version means the latest one.
+// Soft-deleted versions are loaded with IncludeDeleted.
+type InspectRequest struct {
+	Topic          string
+	Record         string
+	Version        int
+	IncludeDeleted bool
+}
+
+// InspectResponse holds all information about the version of the subject.
+type InspectResponse struct {
+	Subject    string               `json:"subject"`
+	ID         int                  `json:"id"`
+	Version    int                  `json:"version"`
+	References []srclient.Reference `json:"references"`
+	Schema     string               `json:"schema"`
+	Deleted    bool                 `json:"deleted,omitempty"`
+}
+
+// Inspect loads the version of the subject. ErrSubjectNotExist is returned for the unknown subject.
+func (c *Client) Inspect(ctx context.Context, request InspectRequest) (*InspectResponse, error) {
+	subject := SubjectName(request.Topic, request.Record)
+	if request.IncludeDeleted {
+		return c.inspectDeleted(ctx, subject, request.Version)
+	}
+
+	schema, err := c.getSchema(subject, request.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InspectResponse{
+		Subject:    subject,
+		ID:         schema.ID(),
+		Version:    schema.Version(),
+		References: schema.References(),
+		Schema:     schema.Schema(),
+	}, nil
+}
+
+// inspectDeleted loads the version of the subject including the soft-deleted one.
+// The zero version means the latest one, which may be soft-deleted.
+func (c *Client) inspectDeleted(ctx context.Context, subject string, version int) (*InspectResponse, error) {
+	active, all, err := c.allVersions(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 && len(all) > 0 {
+		version = all[len(all)-1]
+	}
+
+	schema, err := c.registry.GetSchemaByVersion(ctx, subject, version, true)
+	if err != nil {
+		return nil, fmt.Errorf("error schema: %w", err)
+	}
+
+	return &InspectResponse{
+		Subject:    subject,
+		ID:         schema.ID,
+		Version:    schema.Version,
+		References: schema.References,
+		Schema:     schema.Schema,
+		Deleted:    !containsInt(active, version),
+	}, nil
+}
@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy actions.
+const (
+	PolicyWarn = "warn"
+	PolicyDeny = "deny"
+)
+
+// Policy holds the rules for the schema changes allowed by the registry compatibility.
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// PolicyRule matches the changes with the globs. The empty list matches all. Topics and Records scope the rule,
+// Changes lists the change kinds, Messages and Fields match the full message name and the field name,
+// Old and New match the changed values, e.g. the label `optional`.
+type PolicyRule struct {
+	Name     string   `yaml:"name"`
+	Action   string   `yaml:"action"`
+	Message  string   `yaml:"message"`
+	Topics   []string `yaml:"topics"`
+	Records  []string `yaml:"records"`
+	Changes  []string `yaml:"changes"`
+	Messages []string `yaml:"messages"`
+	Fields   []string `yaml:"fields"`
+	Old      []string `yaml:"old"`
+	New      []string `yaml:"new"`
+}
+
+// PolicyViolation is the change matched by the rule.
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Message string `json:"message"`
+	Change  Change `json:"change"`
+}
+
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("%s: %s (%s): %s", v.Action, v.Message, v.Rule, v.Change)
+}
+
+// LoadPolicy reads the policy rule file.
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("can not read policy: %w", err)
+	}
+	policy := &Policy{}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("can not parse policy %q: %w", file, err)
+	}
+	for i, rule := range policy.Rules {
+		if rule.Action != PolicyWarn && rule.Action != PolicyDeny {
+			return nil, fmt.Errorf("rule %d %q: action must be %s or %s", i, rule.Name, PolicyWarn, PolicyDeny)
+		}
+		for _, globs := range [][]string{rule.Topics, rule.Records, rule.Changes, rule.Messages, rule.Fields, rule.Old, rule.New} {
+			for _, glob := range globs {
+				if _, err := path.Match(glob, ""); err != nil {
+					return nil, fmt.Errorf("rule %d %q: can not use glob %q: %w", i, rule.Name, glob, err)
+				}
+			}
+		}
+	}
+	return policy, nil
+}
+
+// Evaluate matches the changes of the topic record with the rules.
+func (p *Policy) Evaluate(topic, record string, changes []Change) []PolicyViolation {
+	violations := []PolicyViolation{}
+	for _, rule := range p.Rules {
+		if !anyGlobMatch(rule.Topics, topic) || !anyGlobMatch(rule.Records, record) {
+			continue
+		}
+		for _, change := range changes {
+			if !anyGlobMatch(rule.Changes, change.Kind) || !anyGlobMatch(rule.Messages, change.Message) ||
+				!anyGlobMatch(rule.Fields, change.Field) || !anyGlobMatch(rule.Old, change.Old) ||
+				!anyGlobMatch(rule.New, change.New) {
+				continue
+			}
+			violations = append(violations, PolicyViolation{
+				Rule:    rule.Name,
+				Action:  rule.Action,
+				Message: rule.Message,
+				Change:  change,
+			})
+		}
+	}
+	return violations
+}
+
+func anyGlobMatch(globs []string, name string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	for _, glob := range globs {
+		if globMatch(glob, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    int
+		wantErr string
+	}{
+		{name: "empty", policy: "", want: 0},
+		{
+			name: "rules",
+			policy: `rules:
+  - name: no-removal
+    action: deny
+    changes: [field_removed]
+  - name: renames
+    action: warn
+    changes: [field_renamed]
+    topics: ["orders.*"]
+`,
+			want: 2,
+		},
+		{name: "missing action", policy: "rules:\n  - name: bad\n", wantErr: `rule 0 "bad": action must be warn or deny`},
+		{name: "unknown action", policy: "rules:\n  - name: bad\n    action: block\n", wantErr: `rule 0 "bad": action must be warn or deny`},
+		{name: "bad glob", policy: "rules:\n  - name: bad\n    action: warn\n    fields: [\"[a-\"]\n", wantErr: `rule 0 "bad": can not use glob "[a-"`},
+		{name: "bad yaml", policy: "rules: {", wantErr: "can not parse policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "policy.yaml")
+			if err := os.WriteFile(file, []byte(tt.policy), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			policy, err := LoadPolicy(file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadPolicy() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadPolicy() error = %v", err)
+			}
+			if len(policy.Rules) != tt.want {
+				t.Fatalf("LoadPolicy() rules = %d, want %d", len(policy.Rules), tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "can not read policy") {
+		t.Fatalf("LoadPolicy() error = %v, want can not read policy", err)
+	}
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	removed := Change{Kind: ChangeFieldRemoved, Message: "orders.Order", Field: "price", Number: 2, Old: "price"}
+	renamed := Change{Kind: ChangeFieldRenamed, Message: "orders.Order", Field: "total", Number: 3, Old: "sum", New: "total"}
+	optional := Change{Kind: ChangeFieldLabelChanged, Message: "orders.Item", Field: "count", Number: 1, Old: "singular", New: "optional"}
+	repeated := Change{Kind: ChangeFieldLabelChanged, Message: "orders.Item", Field: "tags", Number: 4, Old: "singular", New: "repeated"}
+	changes := []Change{removed, renamed, optional, repeated}
+
+	tests := []struct {
+		name   string
+		rule   PolicyRule
+		topic  string
+		record string
+		want   []Change
+	}{
+		{name: "match all", rule: PolicyRule{}, want: changes},
+		{name: "change kind", rule: PolicyRule{Changes: []string{ChangeFieldRemoved}}, want: []Change{removed}},
+		{name: "change kind glob", rule: PolicyRule{Changes: []string{"field_re*"}}, want: []Change{removed, renamed}},
+		{name: "topic glob", rule: PolicyRule{Topics: []string{"orders.*"}}, want: changes},
+		{name: "other topic", rule: PolicyRule{Topics: []string{"users.*"}}},
+		{name: "record", rule: PolicyRule{Records: []string{"orders.Order"}}, want: changes},
+		{name: "other record", rule: PolicyRule{Records: []string{"orders.Refund"}}},
+		{name: "message", rule: PolicyRule{Messages: []string{"*.Item"}}, want: []Change{optional, repeated}},
+		{name: "field", rule: PolicyRule{Fields: []string{"price", "tags"}}, want: []Change{removed, repeated}},
+		{name: "old value", rule: PolicyRule{Old: []string{"sum"}}, want: []Change{renamed}},
+		{
+			name: "new label",
+			rule: PolicyRule{Changes: []string{ChangeFieldLabelChanged}, New: []string{"optional"}},
+			want: []Change{optional},
+		},
+		{
+			name: "all scopes",
+			rule: PolicyRule{
+				Topics:   []string{"orders.*"},
+				Records:  []string{"orders.Order"},
+				Changes:  []string{ChangeFieldLabelChanged},
+				Messages: []string{"orders.Item"},
+				Fields:   []string{"t*"},
+				New:      []string{"repeated"},
+			},
+			want: []Change{repeated},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Name, tt.rule.Action, tt.rule.Message = tt.name, PolicyWarn, "check the consumers"
+			policy := &Policy{Rules: []PolicyRule{tt.rule}}
+			violations := policy.Evaluate("orders.v1", "orders.Order", changes)
+
+			got := []Change{}
+			for _, violation := range violations {
+				if violation.Rule != tt.name || violation.Action != PolicyWarn || violation.Message != "check the consumers" {
+					t.Fatalf("Evaluate() violation = %+v, want the rule %q", violation, tt.name)
+				}
+				got = append(got, violation.Change)
+			}
+			want := tt.want
+			if want == nil {
+				want = []Change{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Evaluate() changes = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPolicyEvaluateActions(t *testing.T) {
+	policy := &Policy{Rules: []PolicyRule{
+		{Name: "renames", Action: PolicyWarn, Changes: []string{ChangeFieldRenamed}},
+		{Name: "removals", Action: PolicyDeny, Changes: []string{ChangeFieldRemoved}},
+	}}
+
+	tests := []struct {
+		name     string
+		changes  []Change
+		want     []string
+		wantDeny bool
+	}{
+		{name: "no changes", want: []string{}},
+		{name: "added field", changes: []Change{{Kind: ChangeFieldAdded, Field: "price"}}, want: []string{}},
+		{name: "warn", changes: []Change{{Kind: ChangeFieldRenamed, Field: "total"}}, want: []string{PolicyWarn}},
+		{name: "deny", changes: []Change{{Kind: ChangeFieldRemoved, Field: "price"}}, want: []string{PolicyDeny}, wantDeny: true},
+		{
+			name:     "warn and deny",
+			changes:  []Change{{Kind: ChangeFieldRemoved, Field: "price"}, {Kind: ChangeFieldRenamed, Field: "total"}},
+			want:     []string{PolicyWarn, PolicyDeny},
+			wantDeny: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := []string{}
+			denied := false
+			for _, violation := range policy.Evaluate("orders", "orders.Order", tt.changes) {
+				actions = append(actions, violation.Action)
+				denied = denied || violation.Action == PolicyDeny
+			}
+			if !reflect.DeepEqual(actions, tt.want) {
+				t.Fatalf("Evaluate() actions = %v, want %v", actions, tt.want)
+			}
+			if denied != tt.wantDeny {
+				t.Fatalf("Evaluate() denied = %v, want %v", denied, tt.wantDeny)
+			}
+		})
+	}
+}
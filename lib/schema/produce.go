@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"github.com/youla-dev/schema/lib/protoschema"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProduceRequest holds the message in JSON to publish with the version of the registered schema.
+// The zero version means the latest one. The key is optional.
+type ProduceRequest struct {
+	Topic   string
+	Record  string
+	Version int
+	JSON    []byte
+	Key     []byte
+}
+
+// ProduceResponse describes the published message.
+type ProduceResponse struct {
+	Subject   string `json:"subject"`
+	ID        int    `json:"id"`
+	Version   int    `json:"version"`
+	Message   string `json:"message"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// Produce converts the JSON to the record message of the registered schema and publishes it
+// to the topic in the Confluent wire format. ErrSubjectNotExist is returned for the unknown subject.
+func (c *Client) Produce(ctx context.Context, request ProduceRequest) (*ProduceResponse, error) {
+	if c.clusterClient == nil {
+		return nil, ErrNoCluster
+	}
+	subject := SubjectName(request.Topic, request.Record)
+
+	schema, err := c.getSchema(subject, request.Version)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := c.compile(subject+".proto", schema.Schema(), schema.References())
+	if err != nil {
+		return nil, err
+	}
+	md, err := c.recordDescriptor(ctx, fd, schema.Schema(), request.Record)
+	if err != nil {
+		return nil, err
+	}
+
+	message := dynamicpb.NewMessage(md.UnwrapMessage())
+	if err := protojson.Unmarshal(request.JSON, message); err != nil {
+		return nil, fmt.Errorf("can not convert json to %q: %w", md.GetFullyQualifiedName(), err)
+	}
+	body, err := proto.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("can not marshal message: %w", err)
+	}
+	value := append(protoschema.AppendHeader(nil, schema.ID(), protoschema.MessageIndexes(md.UnwrapMessage())), body...)
+
+	producer, err := sarama.NewSyncProducerFromClient(c.clusterClient)
+	if err != nil {
+		return nil, fmt.Errorf("can not create producer: %w", err)
+	}
+	defer producer.Close()
+
+	producerMessage := &sarama.ProducerMessage{
+		Topic: request.Topic,
+		Value: sarama.ByteEncoder(value),
+	}
+	if request.Key != nil {
+		producerMessage.Key = sarama.ByteEncoder(request.Key)
+	}
+	var partition int32
+	var offset int64
+	err = withContext(ctx, func() (err error) {
+		partition, offset, err = producer.SendMessage(producerMessage)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not produce message: %w", err)
+	}
+
+	return &ProduceResponse{
+		Subject:   subject,
+		ID:        schema.ID(),
+		Version:   schema.Version(),
+		Message:   md.GetFullyQualifiedName(),
+		Partition: partition,
+		Offset:    offset,
+	}, nil
+}
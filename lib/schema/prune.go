@@ -0,0 +1,225 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// PruneRequest describes the retention of the versions of the topic subjects. All records of the topic
+// are pruned if the record is empty. The last Keep versions are retained, the versions registered
+// less than OlderThan ago are retained too. The registration time is loaded from the SchemasTopic
+// where the registry stores the schemas, so the cluster is required for OlderThan only.
+// The latest version is never deleted.
+// The permanent prune removes the soft-deleted versions only, as the delete does, keeping the soft-deleted
+// versions among the last Keep versions.
+type PruneRequest struct {
+	Topic        string
+	Record       string
+	Keep         int
+	OlderThan    time.Duration
+	Permanent    bool
+	SchemasTopic string
+}
+
+// PrunePlan lists the deletions of the prune and the versions which are retained despite the rules.
+type PrunePlan struct {
+	Deletions []*DeletePlan `json:"deletions"`
+	Skipped   []PruneSkip   `json:"skipped,omitempty"`
+}
+
+// PruneSkip describes the version retained despite the rules.
+type PruneSkip struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+func (s PruneSkip) String() string {
+	return fmt.Sprintf("Version %d of subject %q is kept: %s", s.Version, s.Subject, s.Reason)
+}
+
+func (p *PrunePlan) String() string {
+	lines := make([]string, 0, len(p.Deletions)+len(p.Skipped))
+	for _, deletion := range p.Deletions {
+		lines = append(lines, deletion.String())
+	}
+	for _, skip := range p.Skipped {
+		lines = append(lines, skip.String())
+	}
+	if len(lines) == 0 {
+		return "Nothing to prune"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PruneResponse is the result of the prune.
+type PruneResponse PrunePlan
+
+func (r *PruneResponse) String() string {
+	lines := make([]string, 0, len(r.Deletions)+len(r.Skipped))
+	for _, deletion := range r.Deletions {
+		lines = append(lines, (*DeleteResponse)(deletion).String())
+	}
+	for _, skip := range r.Skipped {
+		lines = append(lines, skip.String())
+	}
+	if len(lines) == 0 {
+		return "Nothing is pruned"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Prune deletes the versions of the subjects according to the retention rules.
+func (c *Client) Prune(ctx context.Context, request PruneRequest) (*PruneResponse, error) {
+	plan, err := c.PlanPrune(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return c.ApplyPrune(ctx, plan)
+}
+
+// PlanPrune lists the versions to delete according to the retention rules. The versions referenced by
+// other schemas and the protected subjects are skipped.
+func (c *Client) PlanPrune(ctx context.Context, request PruneRequest) (*PrunePlan, error) {
+	if request.Topic == "" {
+		return nil, errors.New("topic is not set")
+	}
+	if request.Keep <= 0 && request.OlderThan <= 0 {
+		return nil, errors.New("retention is not set: set the number of versions to keep or the age")
+	}
+
+	records := []string{request.Record}
+	if request.Record == "" {
+		subjects, err := c.Subjects(ctx, SubjectsRequest{Topic: request.Topic, IncludeDeleted: request.Permanent})
+		if err != nil {
+			return nil, err
+		}
+		records = records[:0]
+		for _, subject := range subjects.Subjects {
+			records = append(records, strings.TrimSuffix(strings.TrimPrefix(subject, request.Topic+"-"), "-value"))
+		}
+	}
+
+	var registered map[SubjectVersion]time.Time
+	if request.OlderThan > 0 {
+		if c.clusterClient == nil {
+			return nil, fmt.Errorf("%w: the cluster is required to read the registration time for the age", ErrNoCluster)
+		}
+		var err error
+		registered, err = c.registrationTimes(ctx, request.SchemasTopic)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &PrunePlan{Deletions: []*DeletePlan{}}
+	for _, record := range records {
+		subject := SubjectName(request.Topic, record)
+		active, all, err := c.allVersions(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, version := range pruneVersions(request, active, all) {
+			if request.OlderThan > 0 {
+				t, ok := registered[SubjectVersion{Subject: subject, Version: version}]
+				if !ok || time.Since(t) < request.OlderThan {
+					continue
+				}
+			}
+
+			deletion, err := c.PlanDelete(ctx, DeleteRequest{
+				Topic:     request.Topic,
+				Record:    record,
+				Version:   version,
+				Permanent: request.Permanent,
+			})
+			var inUseErr *InUseError
+			switch {
+			case errors.Is(err, ErrProtected), errors.As(err, &inUseErr):
+				plan.Skipped = append(plan.Skipped, PruneSkip{Subject: subject, Version: version, Reason: err.Error()})
+			case err != nil:
+				return nil, err
+			default:
+				plan.Deletions = append(plan.Deletions, deletion)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// pruneVersions selects the versions exceeding the number to keep. The latest active version is
+// always kept. The permanent prune selects the soft-deleted versions except the last Keep versions
+// of all, so the recently deleted versions are kept as the recent active ones are.
+func pruneVersions(request PruneRequest, active, all []int) []int {
+	if request.Permanent {
+		sort.Ints(all)
+		keep := request.Keep
+		if keep < 0 {
+			keep = 0
+		}
+		if keep > len(all) {
+			keep = len(all)
+		}
+		var versions []int
+		for _, version := range all[:len(all)-keep] {
+			if !containsInt(active, version) {
+				versions = append(versions, version)
+			}
+		}
+		return versions
+	}
+
+	sort.Ints(active)
+	keep := request.Keep
+	if keep < 1 {
+		keep = 1
+	}
+	if len(active) <= keep {
+		return nil
+	}
+	return active[:len(active)-keep]
+}
+
+// ApplyPrune deletes the versions of the plan.
+func (c *Client) ApplyPrune(ctx context.Context, plan *PrunePlan) (*PruneResponse, error) {
+	for _, deletion := range plan.Deletions {
+		if _, err := c.ApplyDelete(ctx, deletion); err != nil {
+			return nil, fmt.Errorf("can not delete version %d of subject %q: %w", deletion.Version, deletion.Subject, err)
+		}
+	}
+	return (*PruneResponse)(plan), nil
+}
+
+// schemaKey is the key of the schema record in the topic of the registry.
+type schemaKey struct {
+	KeyType string `json:"keytype"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// registrationTimes loads the registration time of the versions from the topic of the registry.
+func (c *Client) registrationTimes(ctx context.Context, schemasTopic string) (map[SubjectVersion]time.Time, error) {
+	registered := map[SubjectVersion]time.Time{}
+	err := c.readTopic(ctx, schemasTopic, 0, 0, func(m *sarama.ConsumerMessage) {
+		var key schemaKey
+		if err := json.Unmarshal(m.Key, &key); err != nil || key.KeyType != "SCHEMA" {
+			return
+		}
+		version := SubjectVersion{Subject: key.Subject, Version: key.Version}
+		if _, ok := registered[version]; !ok {
+			registered[version] = m.Timestamp
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not read registration time from %q: %w", schemasTopic, err)
+	}
+	return registered, nil
+}
@@ -0,0 +1,137 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPruneVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		request PruneRequest
+		active  []int
+		all     []int
+		want    []int
+	}{
+		{name: "keep", request: PruneRequest{Keep: 2}, active: []int{1, 2, 3, 4}, all: []int{1, 2, 3, 4}, want: []int{1, 2}},
+		{name: "keep all", request: PruneRequest{Keep: 5}, active: []int{1, 2, 3}, all: []int{1, 2, 3}},
+		{name: "latest is kept", request: PruneRequest{OlderThan: 1}, active: []int{1, 2, 3}, all: []int{1, 2, 3}, want: []int{1, 2}},
+		{name: "deleted are not soft-deleted again", request: PruneRequest{Keep: 1}, active: []int{3, 4}, all: []int{1, 2, 3, 4}, want: []int{3}},
+		{name: "permanent", request: PruneRequest{Permanent: true, OlderThan: 1}, active: []int{3, 4}, all: []int{1, 2, 3, 4}, want: []int{1, 2}},
+		{name: "permanent keep", request: PruneRequest{Permanent: true, Keep: 3}, active: []int{4, 5}, all: []int{1, 2, 3, 4, 5}, want: []int{1, 2}},
+		{name: "permanent keep deleted", request: PruneRequest{Permanent: true, Keep: 3}, active: []int{5}, all: []int{1, 2, 3, 4, 5}, want: []int{1, 2}},
+		{name: "permanent keep all", request: PruneRequest{Permanent: true, Keep: 10}, active: []int{5}, all: []int{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pruneVersions(tt.request, tt.active, tt.all)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("pruneVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newPruneRegistry serves the versions 1 to 3 of the subject, the version 1 is referenced by the schema 42.
+func newPruneRegistry(t *testing.T, subject string) *Registry {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := "/subjects/" + subject + "/versions"
+		switch {
+		case r.URL.Path == prefix:
+			_, _ = io.WriteString(w, "[1, 2, 3]")
+		case r.URL.Path == prefix+"/1/referencedby":
+			_, _ = io.WriteString(w, "[42]")
+		case strings.HasSuffix(r.URL.Path, "/referencedby"):
+			_, _ = io.WriteString(w, "[]")
+		case strings.HasPrefix(r.URL.Path, prefix+"/"):
+			version := strings.TrimPrefix(r.URL.Path, prefix+"/")
+			_, _ = io.WriteString(w, `{"id": 1`+version+`, "schema": "message A {}"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error_code": 40401, "message": "Subject not found"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return NewRegistry(server.URL)
+}
+
+func TestPlanPruneSkipped(t *testing.T) {
+	subject := SubjectName("orders", "Order")
+	tests := []struct {
+		name      string
+		protected []*regexp.Regexp
+		want      []int
+		reasons   []string
+	}{
+		{
+			name:    "referenced",
+			want:    []int{2},
+			reasons: []string{`schema "orders-Order-value" is in use:` + "\n0 messages scanned\nversion 1 (schema ID 11): 0 messages, referenced by schemas [42]"},
+		},
+		{
+			name:      "protected",
+			protected: []*regexp.Regexp{regexp.MustCompile(`^orders-`)},
+			reasons: []string{
+				`subject is protected: "orders-Order-value" matches "^orders-"`,
+				`subject is protected: "orders-Order-value" matches "^orders-"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(nil, nil).WithRegistry(newPruneRegistry(t, subject)).WithProtectedSubjects(tt.protected...)
+			plan, err := client.PlanPrune(context.Background(), PruneRequest{Topic: "orders", Record: "Order", Keep: 1})
+			if err != nil {
+				t.Fatalf("PlanPrune() error = %v", err)
+			}
+			var versions []int
+			for _, deletion := range plan.Deletions {
+				versions = append(versions, deletion.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.want) {
+				t.Fatalf("PlanPrune() deletions = %v, want %v", versions, tt.want)
+			}
+			var reasons []string
+			for _, skip := range plan.Skipped {
+				reasons = append(reasons, skip.Reason)
+			}
+			if !reflect.DeepEqual(reasons, tt.reasons) {
+				t.Fatalf("PlanPrune() skip reasons = %q, want %q", reasons, tt.reasons)
+			}
+		})
+	}
+}
+
+func TestPlanPruneWithoutCluster(t *testing.T) {
+	client := NewClient(nil, nil).WithRegistry(newPruneRegistry(t, SubjectName("orders", "Order")))
+	_, err := client.PlanPrune(context.Background(), PruneRequest{Topic: "orders", Record: "Order", OlderThan: time.Hour})
+	if !errors.Is(err, ErrNoCluster) {
+		t.Fatalf("PlanPrune() error = %v, want %v", err, ErrNoCluster)
+	}
+}
+
+func TestPruneResponseString(t *testing.T) {
+	response := &PruneResponse{
+		Deletions: []*DeletePlan{{Subject: "orders-Order-value", Version: 1, Versions: []int{1}}},
+		Skipped:   []PruneSkip{{Subject: "orders-Order-value", Version: 2, Reason: "subject is protected"}},
+	}
+	want := "Soft-deleted versions [1] of subject \"orders-Order-value\"\n" +
+		"Version 2 of subject \"orders-Order-value\" is kept: subject is protected"
+	var stringer fmt.Stringer = response
+	if got := stringer.String(); got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+	if got := (&PruneResponse{}).String(); got != "Nothing is pruned" {
+		t.Fatalf("String() = %q, want Nothing is pruned", got)
+	}
+}
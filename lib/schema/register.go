@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/riferrei/srclient"
+)
+
+// RegisterRequest holds the schema to register. Empty topic or record are loaded
+// from the (topic) and (record) options of the schema.
+type RegisterRequest struct {
+	Topic  string
+	Record string
+	Schema []byte
+}
+
+// RegisterResponse is the result of the registration.
+type RegisterResponse struct {
+	Topic   string `json:"topic"`
+	Record  string `json:"record"`
+	Subject string `json:"subject"`
+	Status  Status `json:"status"`
+	ID      int    `json:"id,omitempty"`
+	Version int    `json:"version,omitempty"`
+}
+
+func (r RegisterResponse) String() string {
+	if r.Status == StatusTopicNotExist {
+		return fmt.Sprintf("topic %q not exist", r.Topic)
+	}
+	return fmt.Sprintln("Created schema ID", r.ID, ", version", r.Version)
+}
+
+// Register creates the subject, if one does not exist, or registers the new version of the schema.
+// The schema is not registered if the topic does not exist.
+func (c *Client) Register(ctx context.Context, request RegisterRequest) (*RegisterResponse, error) {
+	topic, record, err := c.topicRecord(ctx, request.Topic, request.Record, request.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RegisterResponse{
+		Topic:   topic,
+		Record:  record,
+		Subject: SubjectName(topic, record),
+	}
+
+	topicExists, err := c.topicExists(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	if !topicExists {
+		response.Status = StatusTopicNotExist
+		return response, nil
+	}
+
+	schema, err := c.schemaRegistryClient.CreateSchema(response.Subject, string(request.Schema), srclient.Protobuf)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the schema %w", err)
+	}
+
+	response.Status = StatusRegistered
+	response.ID = schema.ID()
+	response.Version = schema.Version()
+	return response, nil
+}
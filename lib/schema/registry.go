@@ -0,0 +1,187 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/riferrei/srclient"
+)
+
+// Registry calls the Schema Registry REST API endpoints which are not available in srclient.
+type Registry struct {
+	url                string
+	httpClient         *http.Client
+	username, password string
+}
+
+// NewRegistry creates the client for the Schema Registry URL.
+func NewRegistry(url string) *Registry {
+	return &Registry{
+		url:        strings.TrimSuffix(url, "/"),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SetCredentials sets the basic authentication credentials.
+func (r *Registry) SetCredentials(username, password string) {
+	r.username, r.password = username, password
+}
+
+// SetHTTPClient sets the client for the requests, e.g. the one retrying the failed requests.
+func (r *Registry) SetHTTPClient(client *http.Client) {
+	r.httpClient = client
+}
+
+// RegistryError is the error response of the Schema Registry.
+type RegistryError struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"error_code"`
+	Message    string `json:"message"`
+}
+
+func (e *RegistryError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("schema registry responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("schema registry error %d: %s", e.Code, e.Message)
+}
+
+// RegisteredSchema is the schema loaded by ID or by the version of the subject.
+// Subject, version and ID are set only for the latter.
+type RegisteredSchema struct {
+	Subject    string               `json:"subject,omitempty"`
+	Version    int                  `json:"version,omitempty"`
+	ID         int                  `json:"id,omitempty"`
+	Schema     string               `json:"schema"`
+	SchemaType string               `json:"schemaType"`
+	References []srclient.Reference `json:"references"`
+}
+
+// SubjectVersion is the version of the subject.
+type SubjectVersion struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// GetSchemaByID loads the schema with its references.
+func (r *Registry) GetSchemaByID(ctx context.Context, id int) (*RegisteredSchema, error) {
+	var schema RegisteredSchema
+	if err := r.get(ctx, fmt.Sprintf("/schemas/ids/%d", id), &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// GetSubjectVersionsByID lists the subject&version pairs using the schema.
+func (r *Registry) GetSubjectVersionsByID(ctx context.Context, id int) ([]SubjectVersion, error) {
+	var versions []SubjectVersion
+	if err := r.get(ctx, fmt.Sprintf("/schemas/ids/%d/versions", id), &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *Registry) get(ctx context.Context, path string, result interface{}) error {
+	return r.do(ctx, http.MethodGet, path, nil, result)
+}
+
+func (r *Registry) do(ctx context.Context, method, path string, body io.Reader, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, r.url+path, body)
+	if err != nil {
+		return fmt.Errorf("can not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("can not call schema registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		registryErr := &RegistryError{StatusCode: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(registryErr)
+		return registryErr
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("can not decode schema registry response: %w", err)
+	}
+	return nil
+}
+
+// GetVersions lists the versions of the subject. Soft-deleted versions are included if deleted is set.
+func (r *Registry) GetVersions(ctx context.Context, subject string, deleted bool) ([]int, error) {
+	var versions []int
+	if err := r.get(ctx, fmt.Sprintf("/subjects/%s/versions%s", url.PathEscape(subject), deletedQuery(deleted)), &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetSchemaByVersion loads the version of the subject. Soft-deleted versions are loaded if deleted is set.
+func (r *Registry) GetSchemaByVersion(ctx context.Context, subject string, version int, deleted bool) (*RegisteredSchema, error) {
+	var schema RegisteredSchema
+	path := fmt.Sprintf("/subjects/%s/versions/%d%s", url.PathEscape(subject), version, deletedQuery(deleted))
+	if err := r.get(ctx, path, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// DeleteSubject deletes all versions of the subject. Soft-deleted subject is removed with permanent.
+func (r *Registry) DeleteSubject(ctx context.Context, subject string, permanent bool) ([]int, error) {
+	var versions []int
+	path := fmt.Sprintf("/subjects/%s%s", url.PathEscape(subject), permanentQuery(permanent))
+	if err := r.do(ctx, http.MethodDelete, path, nil, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// DeleteSubjectVersion deletes the version of the subject. Soft-deleted version is removed with permanent.
+func (r *Registry) DeleteSubjectVersion(ctx context.Context, subject string, version int, permanent bool) error {
+	path := fmt.Sprintf("/subjects/%s/versions/%d%s", url.PathEscape(subject), version, permanentQuery(permanent))
+	return r.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// GetReferencedBy lists the IDs of the schemas referencing the version of the subject.
+func (r *Registry) GetReferencedBy(ctx context.Context, subject string, version int) ([]int, error) {
+	var ids []int
+	if err := r.get(ctx, fmt.Sprintf("/subjects/%s/versions/%d/referencedby", url.PathEscape(subject), version), &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// IsNotFound checks the error to be the "not found" response of the Schema Registry.
+func IsNotFound(err error) bool {
+	var registryErr *RegistryError
+	return errors.As(err, &registryErr) && registryErr.StatusCode == http.StatusNotFound
+}
+
+func deletedQuery(deleted bool) string {
+	if deleted {
+		return "?deleted=true"
+	}
+	return ""
+}
+
+func permanentQuery(permanent bool) string {
+	if permanent {
+		return "?permanent=true"
+	}
+	return ""
+}
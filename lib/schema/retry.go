@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy sets the retries of the failed calls to the Schema Registry and the Kafka cluster.
+type RetryPolicy struct {
+	// Retries is the number of the retries after the first attempt.
+	Retries int
+	// Backoff is the delay before the first retry. The delay is doubled for every next retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+func (p RetryPolicy) delay(retry int) time.Duration {
+	delay := p.Backoff
+	for i := 0; i < retry && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// Transport retries the requests failed with the network errors or the 5xx responses with the exponential backoff.
+// Every attempt is limited with AttemptTimeout. The requests created without the context, e.g. by srclient,
+// are bound to Context, so they are cancelled with the command.
+type Transport struct {
+	Base           http.RoundTripper
+	Policy         RetryPolicy
+	AttemptTimeout time.Duration
+	Context        context.Context
+}
+
+// NewHTTPClient creates the client for the Schema Registry retrying the failed requests.
+func NewHTTPClient(ctx context.Context, policy RetryPolicy, attemptTimeout time.Duration) *http.Client {
+	return &http.Client{Transport: &Transport{
+		Base:           http.DefaultTransport,
+		Policy:         policy,
+		AttemptTimeout: attemptTimeout,
+		Context:        ctx,
+	}}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ctx == context.Background() && t.Context != nil {
+		ctx = t.Context
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// The body is read by the first attempt, so the request is retried only if the body can be recreated.
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for retry := 0; ; retry++ {
+		attempt, cancel, err := t.attempt(ctx, req, retry)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := base.RoundTrip(attempt)
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if !failed || !canRetry || retry >= t.Policy.Retries || ctx.Err() != nil {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		cancel()
+
+		timer := time.NewTimer(t.Policy.delay(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *Transport) attempt(ctx context.Context, req *http.Request, retry int) (*http.Request, context.CancelFunc, error) {
+	cancel := context.CancelFunc(func() {})
+	if t.AttemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.AttemptTimeout)
+	}
+	attempt := req.Clone(ctx)
+	if retry > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attempt.Body = body
+	}
+	return attempt, cancel, nil
+}
+
+// cancelBody releases the context of the attempt when the response is read.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// withContext runs the call which does not accept the context, e.g. of sarama, and returns when the context is done
+// without waiting for the call.
+func withContext(ctx context.Context, call func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- call()
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
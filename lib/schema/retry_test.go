@@ -0,0 +1,286 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		want   []time.Duration
+	}{
+		{
+			name:   "exponential",
+			policy: RetryPolicy{Backoff: 100 * time.Millisecond},
+			want:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond},
+		},
+		{
+			name:   "max backoff",
+			policy: RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond},
+			want:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond},
+		},
+		{
+			name:   "backoff above max",
+			policy: RetryPolicy{Backoff: time.Second, MaxBackoff: 500 * time.Millisecond},
+			want:   []time.Duration{500 * time.Millisecond, 500 * time.Millisecond},
+		},
+		{
+			name:   "no backoff",
+			policy: RetryPolicy{},
+			want:   []time.Duration{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for retry, want := range tt.want {
+				if got := tt.policy.delay(retry); got != want {
+					t.Fatalf("delay(%d) = %v, want %v", retry, got, want)
+				}
+			}
+		})
+	}
+}
+
+// failingServer fails the first requests with the status or with the closed connection if the status is 0
+// and records the bodies of all requests.
+type failingServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	status   int
+	bodies   []string
+}
+
+func newFailingServer(t *testing.T, failures, status int) *failingServer {
+	s := &failingServer{failures: failures, status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		fail := len(s.bodies) <= s.failures
+		s.mu.Unlock()
+
+		switch {
+		case fail && s.status == 0:
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Hijack() error = %v", err)
+				return
+			}
+			conn.Close()
+		case fail:
+			w.WriteHeader(s.status)
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *failingServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func newTestTransport(ctx context.Context, retries int) *Transport {
+	return &Transport{
+		// The connections are not reused, so the base transport does not retry the closed connections itself.
+		Base:    &http.Transport{DisableKeepAlives: true},
+		Policy:  RetryPolicy{Retries: retries, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+		Context: ctx,
+	}
+}
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		status       int
+		retries      int
+		noGetBody    bool
+		wantStatus   int
+		wantErr      bool
+		wantRequests int
+	}{
+		{name: "success", failures: 0, status: http.StatusInternalServerError, retries: 3, wantStatus: http.StatusOK, wantRequests: 1},
+		{name: "5xx retried", failures: 2, status: http.StatusServiceUnavailable, retries: 3, wantStatus: http.StatusOK, wantRequests: 3},
+		{name: "5xx exhausted", failures: 5, status: http.StatusInternalServerError, retries: 2, wantStatus: http.StatusInternalServerError, wantRequests: 3},
+		{name: "4xx not retried", failures: 2, status: http.StatusNotFound, retries: 3, wantStatus: http.StatusNotFound, wantRequests: 1},
+		{name: "network error retried", failures: 2, retries: 3, wantStatus: http.StatusOK, wantRequests: 3},
+		{name: "network error exhausted", failures: 5, retries: 1, wantErr: true, wantRequests: 2},
+		{name: "no retries", failures: 1, status: http.StatusBadGateway, retries: 0, wantStatus: http.StatusBadGateway, wantRequests: 1},
+		{name: "body without GetBody", failures: 2, status: http.StatusInternalServerError, retries: 3, noGetBody: true, wantStatus: http.StatusInternalServerError, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFailingServer(t, tt.failures, tt.status)
+			client := &http.Client{Transport: newTestTransport(context.Background(), tt.retries)}
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"schema":"message A {}"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.noGetBody {
+				req.GetBody = nil
+			}
+			resp, err := client.Do(req)
+			if tt.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("Do() error = nil, want error")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Do() error = %v", err)
+				}
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Fatalf("Do() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+			}
+
+			requests := server.requests()
+			if len(requests) != tt.wantRequests {
+				t.Fatalf("requests = %d, want %d", len(requests), tt.wantRequests)
+			}
+			for i, body := range requests {
+				if body != `{"schema":"message A {}"}` {
+					t.Fatalf("request %d body = %q, want the replayed body", i, body)
+				}
+			}
+		})
+	}
+}
+
+func TestTransportCancel(t *testing.T) {
+	tests := []struct {
+		name string
+		// bound cancels the context of the transport instead of the context of the request.
+		bound bool
+	}{
+		{name: "request context"},
+		{name: "transport context", bound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFailingServer(t, 100, http.StatusInternalServerError)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			transport := newTestTransport(context.Background(), 100)
+			transport.Policy.Backoff, transport.Policy.MaxBackoff = time.Hour, time.Hour
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.bound {
+				transport.Context = ctx
+			} else {
+				req = req.WithContext(ctx)
+			}
+
+			go func() {
+				for len(server.requests()) == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				// The failed response is returned to the transport, which waits for the backoff.
+				time.Sleep(20 * time.Millisecond)
+				cancel()
+			}()
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("Do() error = %v, want %v", err, context.Canceled)
+			}
+			if requests := len(server.requests()); requests != 1 {
+				t.Fatalf("requests = %d, want 1", requests)
+			}
+		})
+	}
+}
+
+func TestTransportAttemptTimeout(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		slow := attempts == 1
+		mu.Unlock()
+		if slow {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	transport := newTestTransport(context.Background(), 1)
+	transport.AttemptTimeout = 50 * time.Millisecond
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("Get() body = %q, %v, want ok", body, err)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	errCall := errors.New("call failed")
+
+	t.Run("call result", func(t *testing.T) {
+		if err := withContext(context.Background(), func() error { return errCall }); !errors.Is(err, errCall) {
+			t.Fatalf("withContext() error = %v, want %v", err, errCall)
+		}
+		if err := withContext(context.Background(), func() error { return nil }); err != nil {
+			t.Fatalf("withContext() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("cancelled before call", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		called := false
+		if err := withContext(ctx, func() error { called = true; return nil }); !errors.Is(err, context.Canceled) {
+			t.Fatalf("withContext() error = %v, want %v", err, context.Canceled)
+		}
+		if called {
+			t.Fatalf("withContext() runs the call with the cancelled context")
+		}
+	})
+
+	t.Run("cancelled during call", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		release := make(chan struct{})
+		defer close(release)
+		err := withContext(ctx, func() error {
+			<-release
+			return nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("withContext() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
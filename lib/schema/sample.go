@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/youla-dev/schema/lib/protoschema"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// SampleReport is the result of decoding the messages of the topic with the proposed schema.
+type SampleReport struct {
+	Checked  int             `json:"checked"`
+	Failures []SampleFailure `json:"failures"`
+}
+
+// SampleFailure describes the message which can not be decoded with the proposed schema
+// or loses data.
+type SampleFailure struct {
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+	SchemaID  int    `json:"schema_id,omitempty"`
+	Error     string `json:"error"`
+}
+
+// sample reads the last messages of the topic written with the versions of the subject, decodes them
+// with the registered schema and then with the proposed one. The messages failing to parse with the
+// proposed schema or losing fields and values are reported.
+func (c *Client) sample(ctx context.Context, request ValidateRequest, topic, record string) (*SampleReport, error) {
+	subject := SubjectName(topic, record)
+	latest, err := c.schemaRegistryClient.GetLatestSchema(subject)
+	if err != nil {
+		return nil, fmt.Errorf("error schema: %w", err)
+	}
+
+	// Imports of the proposed schema are resolved with the references of the latest version.
+	proposedFile, err := c.compile("proposed.proto", string(request.Schema), latest.References(), request.ImportDirs...)
+	if err != nil {
+		return nil, err
+	}
+	proposed, err := c.recordDescriptor(ctx, proposedFile, string(request.Schema), record)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := newDecoder(c, topic)
+	report := &SampleReport{Failures: []SampleFailure{}}
+	err = c.readTopic(ctx, topic, 0, request.Sample, func(m *sarama.ConsumerMessage) {
+		schemaID, _, body, err := protoschema.DecodeHeader(m.Value)
+		if err != nil {
+			return
+		}
+		schema, err := decoder.schema(ctx, schemaID)
+		if err == nil && !schema.usedBy(subject) {
+			return
+		}
+
+		report.Checked++
+		failure := SampleFailure{
+			Partition: m.Partition,
+			Offset:    m.Offset,
+			SchemaID:  schemaID,
+		}
+		var registered *dynamicpb.Message
+		if err == nil {
+			registered, _, err = decoder.decodeMessage(ctx, &DecodedMessage{}, m.Value)
+		}
+		if err != nil {
+			failure.Error = fmt.Sprintf("can not decode with the registered schema: %v", err)
+			report.Failures = append(report.Failures, failure)
+			return
+		}
+
+		decoded := dynamicpb.NewMessage(proposed.UnwrapMessage())
+		if err := proto.Unmarshal(body, decoded); err != nil {
+			failure.Error = fmt.Sprintf("can not decode with the proposed schema: %v", err)
+			report.Failures = append(report.Failures, failure)
+			return
+		}
+		if lost := compareMessages("", registered, decoded); len(lost) > 0 {
+			failure.Error = "data is lost with the proposed schema: " + strings.Join(lost, "; ")
+			report.Failures = append(report.Failures, failure)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// compareMessages lists the fields of the registered message which are missing or have other values
+// in the message decoded with the proposed schema. Fields are matched by their numbers.
+func compareMessages(path string, registered, proposed protoreflect.Message) []string {
+	var lost []string
+	if len(proposed.GetUnknown()) > 0 {
+		lost = append(lost, fmt.Sprintf("%sunknown fields", path))
+	}
+
+	proposedFields := proposed.Descriptor().Fields()
+	registered.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := path + string(fd.Name())
+		pfd := proposedFields.ByNumber(fd.Number())
+		if pfd == nil {
+			lost = append(lost, fmt.Sprintf("%s: field %d is removed", name, fd.Number()))
+			return true
+		}
+		if pfd.IsList() != fd.IsList() || pfd.IsMap() != fd.IsMap() {
+			lost = append(lost, fmt.Sprintf("%s: cardinality is changed", name))
+			return true
+		}
+		pv := proposed.Get(pfd)
+
+		switch {
+		case fd.IsList():
+			if v.List().Len() != pv.List().Len() {
+				lost = append(lost, fmt.Sprintf("%s: %d items instead of %d", name, pv.List().Len(), v.List().Len()))
+				return true
+			}
+			for i := 0; i < v.List().Len(); i++ {
+				lost = append(lost, compareValues(fmt.Sprintf("%s[%d]", name, i), fd, pfd, v.List().Get(i), pv.List().Get(i))...)
+			}
+		case fd.IsMap():
+			if v.Map().Len() != pv.Map().Len() {
+				lost = append(lost, fmt.Sprintf("%s: %d entries instead of %d", name, pv.Map().Len(), v.Map().Len()))
+				return true
+			}
+			proposedEntries := map[string]protoreflect.Value{}
+			pv.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				proposedEntries[fmt.Sprint(k.Interface())] = v
+				return true
+			})
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				key := fmt.Sprint(k.Interface())
+				entry, ok := proposedEntries[key]
+				if !ok {
+					lost = append(lost, fmt.Sprintf("%s[%s]: key is lost", name, key))
+					return true
+				}
+				lost = append(lost, compareValues(fmt.Sprintf("%s[%s]", name, key), fd.MapValue(), pfd.MapValue(), v, entry)...)
+				return true
+			})
+		default:
+			if !proposed.Has(pfd) {
+				lost = append(lost, fmt.Sprintf("%s: value is lost", name))
+				return true
+			}
+			lost = append(lost, compareValues(name, fd, pfd, v, pv)...)
+		}
+		return true
+	})
+	return lost
+}
+
+func compareValues(name string, fd, pfd protoreflect.FieldDescriptor, v, pv protoreflect.Value) []string {
+	if fd.Message() != nil || pfd.Message() != nil {
+		if fd.Message() == nil || pfd.Message() == nil {
+			return []string{fmt.Sprintf("%s: type is changed", name)}
+		}
+		return compareMessages(name+".", v.Message(), pv.Message())
+	}
+
+	if scalarString(fd, v) != scalarString(pfd, pv) {
+		return []string{fmt.Sprintf("%s: value %s is decoded as %s", name, scalarString(fd, v), scalarString(pfd, pv))}
+	}
+	return nil
+}
+
+// scalarString formats the scalar value to be compared regardless of the field kind.
+// Enums are compared by numbers, bytes are compared with strings.
+func scalarString(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		return fmt.Sprint(int32(v.Enum()))
+	case protoreflect.BytesKind:
+		return fmt.Sprintf("%q", v.Bytes())
+	case protoreflect.StringKind:
+		return fmt.Sprintf("%q", v.String())
+	}
+	return fmt.Sprint(v.Interface())
+}
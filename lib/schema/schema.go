@@ -0,0 +1,147 @@
+// Package schema implements the schema registry operations of the utility as a Go API:
+// validate, register, delete, versions, subjects, inspect and export.
+// The command line application is a thin layer over this package.
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/riferrei/srclient"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+var (
+	// ErrSubjectNotExist is returned when the subject for the topic&record is not registered yet.
+	ErrSubjectNotExist = errors.New("schema not exist yet")
+	// ErrNotCompatible is returned when the schema is not compatible with the registered version.
+	ErrNotCompatible = errors.New("schema is not compatible")
+	// ErrNoCluster is returned by the operations requiring Kafka cluster when the client has none.
+	ErrNoCluster = errors.New("kafka cluster client is not set")
+	// ErrNoRegistry is returned by the operations requiring Registry when the client has none.
+	ErrNoRegistry = errors.New("schema registry api client is not set")
+)
+
+// errCodeSubjectNotFound is the error code of the Schema Registry for the unknown subject.
+const errCodeSubjectNotFound = 40401
+
+// Client runs the operations against the Schema Registry and the Kafka cluster.
+type Client struct {
+	schemaRegistryClient srclient.ISchemaRegistryClient
+	clusterClient        sarama.Client
+	registry             *Registry
+	protectedSubjects    []*regexp.Regexp
+	options              protoschema.Options
+}
+
+// NewClient creates the client. The cluster client may be nil for the operations
+// working with the Schema Registry only.
+func NewClient(schemaRegistryClient srclient.ISchemaRegistryClient, clusterClient sarama.Client) *Client {
+	return &Client{
+		schemaRegistryClient: schemaRegistryClient,
+		clusterClient:        clusterClient,
+		options:              protoschema.DefaultOptions,
+	}
+}
+
+// WithRegistry sets the client for the Schema Registry endpoints which are not available in srclient.
+func (c *Client) WithRegistry(registry *Registry) *Client {
+	c.registry = registry
+	return c
+}
+
+// WithProtectedSubjects sets the patterns of the subjects which must not be deleted.
+func (c *Client) WithProtectedSubjects(patterns ...*regexp.Regexp) *Client {
+	c.protectedSubjects = patterns
+	return c
+}
+
+// WithOptions sets the topic&record options read from the schemas, the (topic) and (record) options by default.
+func (c *Client) WithOptions(options protoschema.Options) *Client {
+	c.options = options
+	return c
+}
+
+// Options returns the topic&record options read from the schemas.
+func (c *Client) Options() protoschema.Options {
+	return c.options
+}
+
+// SubjectName returns the subject for the value of the topic&record according to TopicRecordNameStrategy.
+func SubjectName(kafkaTopic, record string) string {
+	return protoschema.SubjectName(kafkaTopic, record)
+}
+
+// ParseSubjectName splits the value subject named according to TopicRecordNameStrategy to the topic and the record.
+// The record is expected not to contain "-".
+func ParseSubjectName(subject string) (string, string, bool) {
+	if !strings.HasSuffix(subject, "-value") {
+		return "", "", false
+	}
+	name := strings.TrimSuffix(subject, "-value")
+	i := strings.LastIndex(name, "-")
+	if i <= 0 || i == len(name)-1 {
+		return "", "", false
+	}
+	return name[:i], name[i+1:], true
+}
+
+func (c *Client) topicExists(ctx context.Context, name string) (bool, error) {
+	if c.clusterClient == nil {
+		return false, ErrNoCluster
+	}
+	var topics []string
+	err := withContext(ctx, func() (err error) {
+		topics, err = c.clusterClient.Topics()
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("can not list topics: %w", err)
+	}
+	for _, topic := range topics {
+		if name == topic {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// subjectExists probes the subject with its versions instead of listing all subjects of the registry.
+func (c *Client) subjectExists(name string) (bool, error) {
+	_, err := c.schemaRegistryClient.GetSchemaVersions(name)
+	var registryErr srclient.Error
+	if errors.As(err, &registryErr) && registryErr.Code == errCodeSubjectNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("can not get versions of subject %q: %w", name, err)
+	}
+	return true, nil
+}
+
+// getSchema loads the version of the subject. The zero version means the latest one.
+func (c *Client) getSchema(subject string, version int) (*srclient.Schema, error) {
+	exist, err := c.subjectExists(subject)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, ErrSubjectNotExist
+	}
+
+	var schema *srclient.Schema
+	if version == 0 {
+		schema, err = c.schemaRegistryClient.GetLatestSchema(subject)
+	} else {
+		schema, err = c.schemaRegistryClient.GetSchemaByVersion(subject, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error schema: %w", err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/riferrei/srclient"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+// SearchRequest filters the subjects named according to TopicRecordNameStrategy. Topic and Record are globs,
+// e.g. `orders.*`, an empty glob matches all. Contains is the glob matched against the message and field names
+// of the latest version of the schema.
+type SearchRequest struct {
+	Topic    string
+	Record   string
+	Contains string
+}
+
+// SearchRow is the subject found with its latest version.
+type SearchRow struct {
+	Topic   string `json:"topic"`
+	Record  string `json:"record"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// Search finds the subjects across all topics.
+func (c *Client) Search(ctx context.Context, request SearchRequest) ([]SearchRow, error) {
+	for _, glob := range []string{request.Topic, request.Record, request.Contains} {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("can not use glob %q: %w", glob, err)
+		}
+	}
+
+	subjects, err := c.Subjects(ctx, SubjectsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]SearchRow, 0, len(subjects.Subjects))
+	for _, subject := range subjects.Subjects {
+		topic, record, ok := ParseSubjectName(subject)
+		if !ok || !globMatch(request.Topic, topic) || !globMatch(request.Record, record) {
+			continue
+		}
+		latest, err := c.schemaRegistryClient.GetLatestSchema(subject)
+		if err != nil {
+			return nil, fmt.Errorf("can not get latest version of subject %q: %w", subject, err)
+		}
+		if request.Contains != "" {
+			contains, err := schemaContains(latest, request.Contains)
+			if err != nil {
+				return nil, fmt.Errorf("can not search subject %q: %w", subject, err)
+			}
+			if !contains {
+				continue
+			}
+		}
+		rows = append(rows, SearchRow{
+			Topic:   topic,
+			Record:  record,
+			Subject: subject,
+			Version: latest.Version(),
+		})
+	}
+	return rows, nil
+}
+
+func globMatch(glob, name string) bool {
+	if glob == "" {
+		return true
+	}
+	matched, _ := path.Match(glob, name)
+	return matched
+}
+
+func schemaContains(schema *srclient.Schema, glob string) (bool, error) {
+	if schema.SchemaType() == nil || *schema.SchemaType() != srclient.Protobuf {
+		return false, nil
+	}
+	names, err := protoschema.Names([]byte(schema.Schema()))
+	if err != nil {
+		return false, err
+	}
+	for _, name := range names {
+		if globMatch(glob, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
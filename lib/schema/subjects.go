@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// SubjectsRequest selects the topic to list subjects for. The subjects of all topics are listed if the topic is empty.
+// Soft-deleted subjects are listed with IncludeDeleted.
+type SubjectsRequest struct {
+	Topic          string
+	IncludeDeleted bool
+}
+
+// SubjectsResponse lists the subjects of the topic. Deleted lists the soft-deleted subjects
+// which are also present in Subjects.
+type SubjectsResponse struct {
+	Subjects []string `json:"subjects"`
+	Deleted  []string `json:"deleted,omitempty"`
+}
+
+// Subjects lists the value subjects registered for the topic records.
+func (c *Client) Subjects(ctx context.Context, request SubjectsRequest) (*SubjectsResponse, error) {
+	topic := `.+`
+	if request.Topic != "" {
+		topic = regexp.QuoteMeta(request.Topic)
+	}
+	r, err := regexp.Compile(fmt.Sprintf(`^%s-\w+-value$`, topic))
+	if err != nil {
+		return nil, fmt.Errorf("can not use topic name: %w", err)
+	}
+
+	subjects, err := c.schemaRegistryClient.GetSubjects()
+	if err != nil {
+		return nil, fmt.Errorf("can not get subjects: %w", err)
+	}
+
+	response := &SubjectsResponse{Subjects: filterSubjects(r, subjects)}
+	if !request.IncludeDeleted {
+		return response, nil
+	}
+
+	all, err := c.schemaRegistryClient.GetSubjectsIncludingDeleted()
+	if err != nil {
+		return nil, fmt.Errorf("can not get subjects: %w", err)
+	}
+	active := make(map[string]bool, len(response.Subjects))
+	for _, subject := range response.Subjects {
+		active[subject] = true
+	}
+	response.Subjects = filterSubjects(r, all)
+	for _, subject := range response.Subjects {
+		if !active[subject] {
+			response.Deleted = append(response.Deleted, subject)
+		}
+	}
+	return response, nil
+}
+
+func filterSubjects(r *regexp.Regexp, subjects []string) []string {
+	filtered := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		if r.MatchString(subject) {
+			filtered = append(filtered, subject)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/riferrei/srclient"
+)
+
+// UndeleteRequest selects the soft-deleted version of the subject to restore.
+type UndeleteRequest struct {
+	Topic   string
+	Record  string
+	Version int
+}
+
+// UndeleteResponse describes the restored version. The schema ID of the deleted version is reused,
+// the version number is assigned by the registry.
+type UndeleteResponse struct {
+	Subject        string `json:"subject"`
+	ID             int    `json:"id"`
+	Version        int    `json:"version"`
+	DeletedVersion int    `json:"deleted_version"`
+}
+
+// Undelete registers the exact content of the soft-deleted version again, so the registry reuses its schema ID.
+func (c *Client) Undelete(ctx context.Context, request UndeleteRequest) (*UndeleteResponse, error) {
+	subject := SubjectName(request.Topic, request.Record)
+	if request.Version == 0 {
+		return nil, fmt.Errorf("version of subject %q to restore is not set", subject)
+	}
+	active, _, err := c.allVersions(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	if containsInt(active, request.Version) {
+		return nil, fmt.Errorf("version %d of subject %q is not deleted", request.Version, subject)
+	}
+
+	deleted, err := c.registry.GetSchemaByVersion(ctx, subject, request.Version, true)
+	if err != nil {
+		return nil, fmt.Errorf("error schema: %w", err)
+	}
+
+	schema, err := c.schemaRegistryClient.CreateSchema(subject, deleted.Schema, srclient.Protobuf, deleted.References...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the schema %w", err)
+	}
+	if schema.ID() != deleted.ID {
+		return nil, fmt.Errorf("schema is registered with ID %d instead of %d", schema.ID(), deleted.ID)
+	}
+
+	return &UndeleteResponse{
+		Subject:        subject,
+		ID:             schema.ID(),
+		Version:        schema.Version(),
+		DeletedVersion: request.Version,
+	}, nil
+}
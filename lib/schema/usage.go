@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/youla-dev/schema/lib/protoschema"
+)
+
+// UsageReport describes the usage of the subject versions in the topic data and by the references
+// of other schemas.
+type UsageReport struct {
+	Scanned int           `json:"scanned"`
+	Schemas []SchemaUsage `json:"schemas"`
+}
+
+// SchemaUsage describes the usage of the version of the subject.
+type SchemaUsage struct {
+	Version      int   `json:"version"`
+	SchemaID     int   `json:"schema_id"`
+	Messages     int   `json:"messages"`
+	ReferencedBy []int `json:"referenced_by,omitempty"`
+}
+
+// InUse checks any of the versions to be found in the topic data.
+func (r *UsageReport) InUse() bool {
+	for _, schema := range r.Schemas {
+		if schema.Messages > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Referenced checks any of the versions to be referenced by other schemas.
+func (r *UsageReport) Referenced() bool {
+	for _, schema := range r.Schemas {
+		if len(schema.ReferencedBy) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *UsageReport) String() string {
+	lines := []string{fmt.Sprintf("%d messages scanned", r.Scanned)}
+	for _, schema := range r.Schemas {
+		line := fmt.Sprintf("version %d (schema ID %d): %d messages", schema.Version, schema.SchemaID, schema.Messages)
+		if len(schema.ReferencedBy) > 0 {
+			line += fmt.Sprintf(", referenced by schemas %v", schema.ReferencedBy)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// InUseError is returned when the versions to delete are still used.
+type InUseError struct {
+	Subject string
+	Report  *UsageReport
+}
+
+func (e *InUseError) Error() string {
+	return fmt.Sprintf("schema %q is in use:\n%s", e.Subject, e.Report)
+}
+
+// usage checks the versions of the subject to be referenced by other schemas and to be used
+// by the last messages of every topic partition. The scan is skipped if the topic does not exist or the client
+// has no cluster. The schema ID is looked up in the payload in the Confluent wire format and in the message headers.
+func (c *Client) usage(ctx context.Context, topic, subject string, versions []int, window int64) (*UsageReport, error) {
+	if c.registry == nil {
+		return nil, ErrNoRegistry
+	}
+
+	report := &UsageReport{Schemas: make([]SchemaUsage, 0, len(versions))}
+	ids := make(map[int]int, len(versions))
+	for _, version := range versions {
+		schema, err := c.registry.GetSchemaByVersion(ctx, subject, version, true)
+		if err != nil {
+			return nil, fmt.Errorf("error schema: %w", err)
+		}
+		referencedBy, err := c.registry.GetReferencedBy(ctx, subject, version)
+		if err != nil {
+			return nil, fmt.Errorf("can not get references to version %d: %w", version, err)
+		}
+		ids[schema.ID] = len(report.Schemas)
+		report.Schemas = append(report.Schemas, SchemaUsage{
+			Version:      version,
+			SchemaID:     schema.ID,
+			ReferencedBy: referencedBy,
+		})
+	}
+
+	if window <= 0 || c.clusterClient == nil {
+		return report, nil
+	}
+	topicExists, err := c.topicExists(ctx, topic)
+	if err != nil || !topicExists {
+		return report, err
+	}
+	err = c.readTopic(ctx, topic, 0, window, func(m *sarama.ConsumerMessage) {
+		report.Scanned++
+		for _, id := range messageSchemaIDs(m) {
+			if i, ok := ids[id]; ok {
+				report.Schemas[i].Messages++
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// messageSchemaIDs returns the schema IDs of the payload and of the headers with "schema" in the key.
+// The header value is either the decimal number or the 4 bytes big endian integer.
+func messageSchemaIDs(m *sarama.ConsumerMessage) []int {
+	var ids []int
+	if id, _, _, err := protoschema.DecodeHeader(m.Value); err == nil {
+		ids = append(ids, id)
+	}
+	for _, header := range m.Headers {
+		if header == nil || !strings.Contains(strings.ToLower(string(header.Key)), "schema") {
+			continue
+		}
+		if id, err := strconv.Atoi(string(header.Value)); err == nil {
+			ids = append(ids, id)
+		} else if len(header.Value) == 4 {
+			ids = append(ids, int(binary.BigEndian.Uint32(header.Value)))
+		}
+	}
+	return ids
+}
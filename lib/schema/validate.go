@@ -0,0 +1,190 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/riferrei/srclient"
+)
+
+// Status describes the outcome of validate and register operations.
+type Status string
+
+const (
+	StatusTopicNotExist   Status = "topic_not_exist"
+	StatusSubjectNotExist Status = "subject_not_exist"
+	StatusCompatible      Status = "compatible"
+	StatusRegistered      Status = "registered"
+	StatusSampleFailed    Status = "sample_failed"
+	StatusPolicyDenied    Status = "policy_denied"
+)
+
+// ValidateRequest holds the schema to validate. Empty topic or record are loaded
+// from the (topic) and (record) options of the schema.
+// If Sample is set, the last messages of every partition are decoded with the schema.
+// ImportDirs are used to resolve the imports of the schema for decoding.
+// If Policy is set, its rules are evaluated on the changes between the latest version and the schema.
+type ValidateRequest struct {
+	Topic      string
+	Record     string
+	Schema     []byte
+	Sample     int64
+	ImportDirs []string
+	Policy     *Policy
+}
+
+// ValidateResponse is the result of the successful validation.
+type ValidateResponse struct {
+	Topic   string `json:"topic"`
+	Record  string `json:"record"`
+	Subject string `json:"subject"`
+	Status  Status `json:"status"`
+
+	Sample *SampleReport     `json:"sample,omitempty"`
+	Policy []PolicyViolation `json:"policy,omitempty"`
+}
+
+func (r ValidateResponse) String() string {
+	var violations []string
+	for _, violation := range r.Policy {
+		violations = append(violations, violation.String())
+	}
+	if r.Status == StatusPolicyDenied {
+		return strings.Join(append([]string{"schema changes are denied by the policy"}, violations...), "\n")
+	}
+	if len(violations) > 0 {
+		return strings.Join(append([]string{r.statusString()}, violations...), "\n")
+	}
+	return r.statusString()
+}
+
+func (r ValidateResponse) statusString() string {
+	switch r.Status {
+	case StatusTopicNotExist:
+		return fmt.Sprintf("topic %q not exist", r.Topic)
+	case StatusSubjectNotExist:
+		return fmt.Sprintf("schema %q not exist yet", r.Subject)
+	case StatusSampleFailed:
+		lines := []string{fmt.Sprintf("%d of %d sampled messages fail with the schema", len(r.Sample.Failures), r.Sample.Checked)}
+		for _, failure := range r.Sample.Failures {
+			lines = append(lines, fmt.Sprintf("partition %d offset %d: %s", failure.Partition, failure.Offset, failure.Error))
+		}
+		return strings.Join(lines, "\n")
+	}
+	if r.Sample != nil {
+		return fmt.Sprintf("schema is compatible, %d sampled messages are decoded", r.Sample.Checked)
+	}
+	return "schema is compatible"
+}
+
+// Validate checks the topic to exist and the schema to be compatible with the latest registered version.
+// The schema is valid if the topic or the subject does not exist. ErrNotCompatible is returned for
+// the incompatible schema. The changes denied by the policy are reported with StatusPolicyDenied,
+// the sampled messages failing with the schema are reported with StatusSampleFailed.
+func (c *Client) Validate(ctx context.Context, request ValidateRequest) (*ValidateResponse, error) {
+	topic, record, err := c.topicRecord(ctx, request.Topic, request.Record, request.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ValidateResponse{
+		Topic:   topic,
+		Record:  record,
+		Subject: SubjectName(topic, record),
+	}
+
+	topicExists, err := c.topicExists(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	if !topicExists {
+		response.Status = StatusTopicNotExist
+		return response, nil
+	}
+
+	subjectExist, err := c.subjectExists(response.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if !subjectExist {
+		response.Status = StatusSubjectNotExist
+		return response, nil
+	}
+
+	compatible, err := c.schemaRegistryClient.IsSchemaCompatible(response.Subject, string(request.Schema), "latest", srclient.Protobuf)
+	if err != nil {
+		return nil, fmt.Errorf("error validating schema: %w", err)
+	}
+	if !compatible {
+		return nil, ErrNotCompatible
+	}
+
+	response.Status = StatusCompatible
+	if request.Policy != nil {
+		changes, err := c.diff(ctx, request, response.Subject, record)
+		if err != nil {
+			return nil, err
+		}
+		response.Policy = request.Policy.Evaluate(topic, record, changes)
+		for _, violation := range response.Policy {
+			if violation.Action == PolicyDeny {
+				response.Status = StatusPolicyDenied
+				return response, nil
+			}
+		}
+	}
+	if request.Sample > 0 {
+		response.Sample, err = c.sample(ctx, request, topic, record)
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Sample.Failures) > 0 {
+			response.Status = StatusSampleFailed
+		}
+	}
+	return response, nil
+}
+
+// diff lists the changes of the record message between the latest version of the subject and the schema.
+func (c *Client) diff(ctx context.Context, request ValidateRequest, subject, record string) ([]Change, error) {
+	latest, err := c.schemaRegistryClient.GetLatestSchema(subject)
+	if err != nil {
+		return nil, fmt.Errorf("error schema: %w", err)
+	}
+	registeredFile, err := c.compile("registered.proto", latest.Schema(), latest.References())
+	if err != nil {
+		return nil, err
+	}
+	registered, err := c.recordDescriptor(ctx, registeredFile, latest.Schema(), record)
+	if err != nil {
+		return nil, err
+	}
+
+	// Imports of the proposed schema are resolved with the references of the latest version.
+	proposedFile, err := c.compile("proposed.proto", string(request.Schema), latest.References(), request.ImportDirs...)
+	if err != nil {
+		return nil, err
+	}
+	proposed, err := c.recordDescriptor(ctx, proposedFile, string(request.Schema), record)
+	if err != nil {
+		return nil, err
+	}
+	return DiffMessages(registered, proposed), nil
+}
+
+// topicRecord completes the empty topic or record with the options of the schema.
+func (c *Client) topicRecord(ctx context.Context, topic, record string, schema []byte) (string, string, error) {
+	if topic == "" || record == "" {
+		var err error
+		topic, record, err = c.options.Parse(ctx, schema)
+		if err != nil {
+			return "", "", fmt.Errorf("can not extract topic and record from proto: %w", err)
+		}
+	}
+
+	if topic == "" {
+		return "", "", fmt.Errorf("topic %q is invalid", topic)
+	}
+	return topic, record, nil
+}
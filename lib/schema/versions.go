@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// VersionsRequest selects the subject to list versions for.
+// Soft-deleted versions are listed with IncludeDeleted.
+type VersionsRequest struct {
+	Topic          string
+	Record         string
+	IncludeDeleted bool
+}
+
+// VersionsResponse lists the versions of the subject. Deleted lists the soft-deleted versions
+// which are also present in Versions.
+type VersionsResponse struct {
+	Subject  string `json:"subject"`
+	Versions []int  `json:"versions"`
+	Deleted  []int  `json:"deleted,omitempty"`
+}
+
+// IsDeleted checks the version to be soft-deleted.
+func (r *VersionsResponse) IsDeleted(version int) bool {
+	return containsInt(r.Deleted, version)
+}
+
+// Versions lists the available versions of the subject. ErrSubjectNotExist is returned for the unknown subject.
+func (c *Client) Versions(ctx context.Context, request VersionsRequest) (*VersionsResponse, error) {
+	subject := SubjectName(request.Topic, request.Record)
+
+	if request.IncludeDeleted {
+		active, all, err := c.allVersions(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		response := &VersionsResponse{Subject: subject, Versions: all}
+		for _, version := range all {
+			if !containsInt(active, version) {
+				response.Deleted = append(response.Deleted, version)
+			}
+		}
+		return response, nil
+	}
+
+	exist, err := c.subjectExists(subject)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, ErrSubjectNotExist
+	}
+
+	versions, err := c.schemaRegistryClient.GetSchemaVersions(subject)
+	if err != nil {
+		return nil, fmt.Errorf("error getting versions: %w", err)
+	}
+	return &VersionsResponse{
+		Subject:  subject,
+		Versions: versions,
+	}, nil
+}
+
+// allVersions lists the active versions of the subject and all versions including the soft-deleted ones.
+func (c *Client) allVersions(ctx context.Context, subject string) ([]int, []int, error) {
+	if c.registry == nil {
+		return nil, nil, ErrNoRegistry
+	}
+	active, err := c.registry.GetVersions(ctx, subject, false)
+	if err != nil && !IsNotFound(err) {
+		return nil, nil, fmt.Errorf("error getting versions: %w", err)
+	}
+	all, err := c.registry.GetVersions(ctx, subject, true)
+	if IsNotFound(err) {
+		return nil, nil, ErrSubjectNotExist
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting versions: %w", err)
+	}
+	return active, all, nil
+}